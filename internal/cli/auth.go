@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/auth"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// authOptions configures bearer-token authentication for the http transport.
+type authOptions struct {
+	tokensFile string
+	jwksFile   string
+	issuer     string
+	audience   string
+}
+
+// verifier builds a token verifier from the configured sources. It returns
+// nil when no authentication source is configured.
+func (o authOptions) verifier() (mcpauth.TokenVerifier, error) {
+	var verifiers []mcpauth.TokenVerifier
+	if o.tokensFile != "" {
+		static, err := auth.LoadStaticTokens(o.tokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading static tokens: %w", err)
+		}
+		verifiers = append(verifiers, static.Verify)
+	}
+	if o.jwksFile != "" {
+		jwtVerifier, err := auth.LoadJWTVerifier(o.jwksFile, auth.JWTOptions{
+			Issuer:   o.issuer,
+			Audience: o.audience,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading JWKS: %w", err)
+		}
+		verifiers = append(verifiers, jwtVerifier.Verify)
+	} else if o.issuer != "" || o.audience != "" {
+		return nil, fmt.Errorf("--auth-issuer and --auth-audience require --auth-jwks-file")
+	}
+
+	switch len(verifiers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return verifiers[0], nil
+	default:
+		return auth.ChainVerifiers(verifiers...), nil
+	}
+}
+
+// authorizeToolCalls rejects tool calls on srv unless the request's bearer
+// token carries one of the tool's scopes. It is installed once, when the
+// server is built, and must be paired with a token verifier.
+func authorizeToolCalls(srv *mcp.Server) {
+	srv.AddReceivingMiddleware(auth.RequireToolScopes(server.ToolScopes))
+}
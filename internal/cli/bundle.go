@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cuelang.org/go/mod/modconfig"
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/spf13/cobra"
+)
+
+func bundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Manage offline schema bundles",
+	}
+	cmd.AddCommand(bundleCreateCmd())
+	return cmd
+}
+
+func bundleCreateCmd() *cobra.Command {
+	var (
+		output   string
+		versions []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Download Gemara schema versions into a bundle for offline use with serve --schema-bundle",
+		Example: "gemara-mcp bundle create --output gemara-schemas.tar.gz\ngemara-mcp bundle create --output ./schemas --version v1.0.0 --version latest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, err := modconfig.NewRegistry(nil)
+			if err != nil {
+				return fmt.Errorf("creating CUE registry: %w", err)
+			}
+
+			archive := isTarball(output)
+			dir := output
+			if archive {
+				dir, err = os.MkdirTemp("", "gemara-schema-bundle-")
+				if err != nil {
+					return fmt.Errorf("creating bundle directory: %w", err)
+				}
+				defer func() { _ = os.RemoveAll(dir) }()
+			}
+
+			// Migrations import a pinned module version, which is bundled
+			// so that migrate works offline as well.
+			migrationVersions, err := server.MigrationSchemaVersions()
+			if err != nil {
+				return err
+			}
+			versions = append(versions, migrationVersions...)
+
+			written, err := schema.CreateBundle(cmd.Context(), reg, dir, server.GemaraModulePath, versions)
+			if err != nil {
+				return fmt.Errorf("creating schema bundle: %w", err)
+			}
+			if archive {
+				if err := writeBundleArchive(dir, output); err != nil {
+					return err
+				}
+			}
+
+			for _, mv := range written {
+				fmt.Fprintln(cmd.OutOrStdout(), mv.String())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "bundle directory, or tarball path ending in .tar.gz or .tgz")
+	cmd.Flags().StringSliceVar(&versions, "version", []string{"latest"}, "Gemara schema versions to include (semver tag or \"latest\"; repeatable); the versions imported by the built-in migrations are always included")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func isTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+func writeBundleArchive(dir, path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating bundle archive: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	return schema.ArchiveBundle(dir, f)
+}
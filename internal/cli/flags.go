@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"log/slog"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/spf13/cobra"
+)
+
+// schemaFlags selects where the validate and migrate commands load schemas
+// from, as serve does.
+type schemaFlags struct {
+	bundle   string
+	cacheDir string
+}
+
+func (f *schemaFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.bundle, "schema-bundle", "", "directory or tarball created by \"bundle create\"; schemas are loaded from it without network access")
+	cmd.Flags().StringVar(&f.cacheDir, "cache-dir", "", "directory for a persistent schema cache, which may be shared with serve --cache-dir (default: in-memory only)")
+}
+
+// schemas returns the schema source selected by the flags and a function
+// that releases it.
+func (f *schemaFlags) schemas() (server.SchemaSource, func(), error) {
+	var opts []server.ModeOption
+	release := func() {}
+	if f.cacheDir != "" {
+		opts = append(opts, server.WithCacheDir(f.cacheDir))
+	}
+	if f.bundle != "" {
+		bundle, err := schema.OpenBundle(f.bundle)
+		if err != nil {
+			return server.SchemaSource{}, nil, err
+		}
+		slog.Debug("loading schemas from offline bundle", "bundle", f.bundle)
+		opts = append(opts, server.WithSchemaRegistry(bundle))
+		release = func() { _ = bundle.Close() }
+	}
+
+	mode, err := server.NewAdvisoryMode(defaultCacheTTL, opts...)
+	if err != nil {
+		release()
+		return server.SchemaSource{}, nil, err
+	}
+	return mode.Schemas(), release, nil
+}
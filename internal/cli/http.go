@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultListenAddr = "localhost:8080"
+	defaultBasePath   = "/mcp"
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+// httpOptions configures the streamable HTTP transport.
+type httpOptions struct {
+	addr     string
+	basePath string
+
+	// verifier authenticates bearer tokens. When nil, requests are not authenticated.
+	verifier mcpauth.TokenVerifier
+
+	tls tlsOptions
+}
+
+// validate checks that the HTTP options are usable before binding a listener.
+func (o httpOptions) validate() error {
+	if o.addr == "" {
+		return fmt.Errorf("listen address is required for http transport")
+	}
+	if !strings.HasPrefix(o.basePath, "/") {
+		return fmt.Errorf("base path %q must start with \"/\"", o.basePath)
+	}
+	return o.tls.validate()
+}
+
+// newHTTPHandler returns an http.Handler that serves the MCP server over
+// streamable HTTP (with SSE streaming) at the configured base path. When a
+// verifier is configured, every request must carry a valid bearer token;
+// tool calls are authorized by the middleware authorizeToolCalls installs.
+func newHTTPHandler(srv *mcp.Server, opts httpOptions) http.Handler {
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return srv
+	}, nil)
+
+	if opts.verifier != nil {
+		handler = mcpauth.RequireBearerToken(opts.verifier, nil)(handler)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(opts.basePath, handler)
+	return mux
+}
+
+// serveHTTP serves the MCP server over streamable HTTP until ctx is cancelled,
+// then shuts the listener down gracefully. When TLS is configured, the
+// certificate files are watched and reloaded on change.
+func serveHTTP(ctx context.Context, srv *mcp.Server, opts httpOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.verifier == nil {
+		slog.Warn("serving streamable HTTP without authentication; configure --auth-tokens-file or --auth-jwks-file for shared instances")
+	}
+
+	httpServer := &http.Server{
+		Handler:           newHTTPHandler(srv, opts),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	var reloader *certReloader
+	if opts.tls.enabled() {
+		var err error
+		reloader, err = newCertReloader(opts.tls)
+		if err != nil {
+			return err
+		}
+		httpServer.TLSConfig = reloader.tlsConfig()
+		go reloader.watch(ctx, tlsReloadInterval)
+	} else {
+		slog.Warn("serving streamable HTTP without TLS; configure --tls-cert and --tls-key or terminate TLS in front of the server")
+	}
+
+	ln, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", opts.addr, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("serving streamable HTTP", "addr", ln.Addr().String(), "path", opts.basePath,
+			"tls", reloader != nil, "mtls", opts.tls.clientCAFile != "")
+		if reloader != nil {
+			errCh <- httpServer.ServeTLS(ln, "", "")
+			return
+		}
+		errCh <- httpServer.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("serving HTTP: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down HTTP server: %w", err)
+	}
+	slog.Info("streamable HTTP server stopped")
+	return nil
+}
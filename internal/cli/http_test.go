@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        httpOptions
+		errContains string
+	}{
+		{
+			name: "defaults are valid",
+			opts: httpOptions{addr: defaultListenAddr, basePath: defaultBasePath},
+		},
+		{
+			name:        "missing listen address",
+			opts:        httpOptions{basePath: defaultBasePath},
+			errContains: "listen address",
+		},
+		{
+			name:        "relative base path",
+			opts:        httpOptions{addr: defaultListenAddr, basePath: "mcp"},
+			errContains: "must start with",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.validate()
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewHTTPHandlerServesBasePath(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.0"}, nil)
+	ts := httptest.NewServer(newHTTPHandler(server, httpOptions{addr: defaultListenAddr, basePath: "/gemara"}))
+	t.Cleanup(ts.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: ts.URL + "/gemara"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+
+	resp, err := http.Get(ts.URL + "/other")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// bearerTransport adds a bearer token to every outgoing request.
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewHTTPHandlerRequiresAuthentication(t *testing.T) {
+	verifier, err := auth.NewStaticTokenVerifier([]auth.StaticToken{
+		{Name: "reader", Token: "reader-token", Scopes: []string{server.ScopeAdvisory}},
+	})
+	require.NoError(t, err)
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.0"}, nil)
+	mcp.AddTool(srv, server.MetadataMigrateGemaraArtifact, func(ctx context.Context, req *mcp.CallToolRequest, input server.InputMigrateGemaraArtifact) (*mcp.CallToolResult, server.OutputMigrateGemaraArtifact, error) {
+		return server.MigrateGemaraArtifact(ctx, req, input, server.SchemaSource{}, nil)
+	})
+	authorizeToolCalls(srv)
+	ts := httptest.NewServer(newHTTPHandler(srv, httpOptions{
+		addr:     defaultListenAddr,
+		basePath: defaultBasePath,
+		verifier: verifier.Verify,
+	}))
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Post(ts.URL+defaultBasePath, "application/json", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "requests without a token are rejected")
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   ts.URL + defaultBasePath,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "reader-token"}},
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	_, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      server.MetadataMigrateGemaraArtifact.Name,
+		Arguments: map[string]any{"artifact_content": "metadata: {}"},
+	})
+	require.Error(t, err, "advisory token must not call artifact tools")
+	assert.Contains(t, err.Error(), "requires one of scopes")
+}
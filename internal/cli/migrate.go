@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/report"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/spf13/cobra"
+)
+
+// migration is the result of the migrate command.
+type migration struct {
+	server.OutputMigrateGemaraArtifact
+	// Written lists the files the migrated artifacts were written to.
+	Written []string `json:"written,omitempty"`
+}
+
+func migrateCmd() *cobra.Command {
+	var (
+		input    server.InputMigrateGemaraArtifact
+		output   string
+		format   string
+		strict   bool
+		rulesDir string
+		schemas  schemaFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate <file>",
+		Short: "Migrate a Gemara artifact to a newer schema version",
+		Long: `Migrate a Gemara artifact to a newer schema version, as the migrate_gemara_artifact tool does, and write
+each migrated artifact to the output directory under its suggested file name.
+
+Exits 0 on success, 1 when a migrated artifact fails validation or, with --strict, placeholders remain
+(nothing is written then), and 2 when the artifact could not be migrated.`,
+		Example: "gemara-mcp migrate threats.yaml -o v1/\ngemara-mcp migrate controls.yaml --dry-run\ngemara-mcp migrate controls.yaml -o v1/ --strict --override ControlCatalog:metadata.id=ORG-CC",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkFormat(format); err != nil {
+				return err
+			}
+			quietLogs()
+			if output == "" && !input.DryRun {
+				return fmt.Errorf("--output is required unless --dry-run is set")
+			}
+			content, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			input.ArtifactContent = string(content)
+
+			var packs []server.RulePack
+			if rulesDir != "" {
+				if packs, err = server.LoadRulePacks(rulesDir); err != nil {
+					return fmt.Errorf("loading migration rule packs: %w", err)
+				}
+			}
+			source, release, err := schemas.schemas()
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			_, out, err := server.MigrateGemaraArtifact(cmd.Context(), nil, input, source, packs)
+			if err != nil {
+				return err
+			}
+			result := migration{OutputMigrateGemaraArtifact: out}
+
+			paths := make([]string, len(out.Artifacts))
+			invalid := 0
+			for i, a := range out.Artifacts {
+				paths[i] = filepath.Join(output, a.SuggestedFilename)
+				if a.Validation != nil && !a.Validation.Valid {
+					invalid++
+				}
+			}
+			blocked := strict && len(out.TODOs) > 0
+			if !input.DryRun && !blocked {
+				if err := os.MkdirAll(output, 0o755); err != nil {
+					return fmt.Errorf("creating output directory: %w", err)
+				}
+				for i, a := range out.Artifacts {
+					if err := os.WriteFile(paths[i], []byte(a.Content), 0o644); err != nil {
+						return fmt.Errorf("writing migrated %s: %w", a.Type, err)
+					}
+					result.Written = append(result.Written, paths[i])
+				}
+			}
+
+			if err := writeMigration(cmd.OutOrStdout(), format, result, paths, input.DryRun); err != nil {
+				return err
+			}
+			if blocked {
+				return findings("strict: %d placeholder(s) need values in --override; nothing written", len(out.TODOs))
+			}
+			if invalid > 0 {
+				return findings("%d migrated artifact(s) failed validation", invalid)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "directory the migrated artifacts are written to")
+	cmd.Flags().StringVar(&input.TargetVersion, "target-version", "", "gemara-version to migrate to (default: "+server.DefaultGemaraVersion+")")
+	cmd.Flags().StringVar(&input.ArtifactType, "artifact-type", "", "artifact type when metadata.type is missing")
+	cmd.Flags().StringVar(&input.GemaraVersion, "gemara-version", "", "source gemara-version when metadata.gemara-version is missing")
+	cmd.Flags().StringToStringVar(&input.Overrides, "override", nil, "value for a REPLACE ME placeholder, as <artifact type>:<YAML path>=<value> or <YAML path>=<value> (repeatable)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "fail without writing anything while any REPLACE ME placeholder has no --override")
+	cmd.Flags().BoolVar(&input.DryRun, "dry-run", false, "report the changes and diffs without writing the migrated artifacts")
+	cmd.Flags().StringVar(&rulesDir, "migration-rules-dir", "", "directory of CUE rule packs (*.cue in package migrate) applied after the built-in migrations")
+	cmd.Flags().StringVar(&format, "format", formatHuman, "output format: human, json, sarif or junit")
+	schemas.register(cmd)
+
+	return cmd
+}
+
+// writeMigration reports the migration; paths are the files each migrated
+// artifact is, or in a dry run would be, written to.
+func writeMigration(w io.Writer, format string, result migration, paths []string, dryRun bool) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, result)
+	case formatSARIF, formatJUnit:
+		var artifacts []report.Artifact
+		for i, a := range result.Artifacts {
+			schemaFindings := report.Artifact{Path: paths[i], Rule: report.RuleSchema}
+			if v := a.Validation; v != nil && !v.Valid {
+				schemaFindings.Diagnostics = v.Diagnostics
+				schemaFindings.Errors = v.Errors
+			}
+			todos := report.Artifact{Path: paths[i], Rule: report.RuleMigrationTODO}
+			for _, todo := range result.TODOs {
+				if todo.Artifact == a.Type {
+					todos.Diagnostics = append(todos.Diagnostics, todoDiagnostic(todo))
+				}
+			}
+			artifacts = append(artifacts, schemaFindings, todos)
+		}
+		return report.Write(w, format, GetVersion(), artifacts)
+	}
+
+	fmt.Fprintln(w, result.Message)
+	for _, change := range result.Changes {
+		fmt.Fprintf(w, "  - %s\n", change)
+	}
+	for i, a := range result.Artifacts {
+		status := "not validated"
+		if v := a.Validation; v != nil && v.Valid {
+			status = fmt.Sprintf("valid (%s, schema %s)", v.Definition, v.Version)
+		} else if v != nil {
+			status = fmt.Sprintf("invalid (%s, schema %s)", v.Definition, v.Version)
+		}
+		fmt.Fprintf(w, "%s: %s\n", paths[i], status)
+		if v := a.Validation; v != nil && !v.Valid {
+			for _, d := range v.Diagnostics {
+				fmt.Fprintf(w, "  %s\n", d)
+			}
+		}
+		for _, todo := range result.TODOs {
+			if todo.Artifact == a.Type {
+				fmt.Fprintf(w, "  %s\n", todoDiagnostic(todo))
+			}
+		}
+		if dryRun {
+			fmt.Fprint(w, a.UnifiedDiff)
+		}
+	}
+	return nil
+}
+
+// todoDiagnostic reports a migration TODO as a warning at its placeholder.
+func todoDiagnostic(todo server.MigrationTODO) schema.Diagnostic {
+	return schema.Diagnostic{
+		Path:     todo.Path,
+		Line:     todo.Line,
+		Severity: schema.SeverityWarning,
+		Message:  fmt.Sprintf("TODO: %s (--override %s=...)", todo.Description, todo.Override),
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/report"
+)
+
+// Exit codes of the validate and migrate commands, for pre-commit hooks and
+// CI gates. Every other failure exits with exitFailure.
+const (
+	exitFindings = 1
+	exitFailure  = 2
+)
+
+// Output formats of the validate and migrate commands.
+const (
+	formatHuman = "human"
+	formatJSON  = "json"
+	formatSARIF = report.FormatSARIF
+	formatJUnit = report.FormatJUnit
+)
+
+// exitCodeError is an error that sets the process exit code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// findings returns an error that exits with exitFindings.
+func findings(format string, args ...any) error {
+	return &exitCodeError{code: exitFindings, err: fmt.Errorf(format, args...)}
+}
+
+// ExitCode returns the process exit code for an error returned by the root
+// command: 0 for nil, 1 when validate or migrate reported invalid artifacts,
+// and 2 for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *exitCodeError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitFailure
+}
+
+// quietLogs limits logging to warnings, so the progress of each tool call
+// does not bury the report.
+func quietLogs() {
+	slog.SetLogLoggerLevel(slog.LevelWarn)
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case formatHuman, formatJSON, formatSARIF, formatJUnit:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q: must be \"human\", \"json\", \"sarif\" or \"junit\"", format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
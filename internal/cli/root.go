@@ -4,14 +4,19 @@ package cli
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
 
-const defaultCacheTTL = 1 * time.Hour
+const (
+	defaultCacheTTL        = 1 * time.Hour
+	defaultCacheMaxEntries = 32
+)
 
 // New creates the root command
 func New() *cobra.Command {
@@ -21,6 +26,9 @@ func New() *cobra.Command {
 	}
 	cmd.AddCommand(
 		serveCmd(),
+		validateCmd(),
+		migrateCmd(),
+		bundleCmd(),
 		versionCmd,
 	)
 	return cmd
@@ -35,22 +43,61 @@ var versionCmd = &cobra.Command{
 }
 
 func serveCmd() *cobra.Command {
-	var modeName string
+	var (
+		modeName      string
+		transportName string
+		httpOpts      httpOptions
+		authOpts      authOptions
+		schemaBundle  string
+		cacheDir      string
+		rulesDir      string
+		workspace     string
+		staleCache    bool
+		cacheTTL      time.Duration
+		cacheConfig   server.CacheConfig
+	)
 
 	cmd := &cobra.Command{
 		Use:     "serve",
 		Short:   "Start the Gemara MCP server",
-		Example: "gemara-mcp serve\ngemara-mcp serve --mode advisory",
+		Example: "gemara-mcp serve\ngemara-mcp serve --mode advisory\ngemara-mcp serve --transport http --listen 0.0.0.0:8080 --base-path /mcp",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cacheTTL <= 0 || cacheConfig.SchemaTTL < 0 || cacheConfig.LexiconTTL < 0 || cacheConfig.VersionTTL < 0 {
+				return fmt.Errorf("cache TTLs must be positive")
+			}
+
 			var (
-				mode server.Mode
-				err  error
+				mode     server.Mode
+				modeOpts = []server.ModeOption{server.WithCacheConfig(cacheConfig)}
+				err      error
 			)
+			if cacheDir != "" {
+				modeOpts = append(modeOpts, server.WithCacheDir(cacheDir))
+			}
+			if staleCache {
+				modeOpts = append(modeOpts, server.WithStaleWhileRevalidate())
+			}
+			if rulesDir != "" {
+				modeOpts = append(modeOpts, server.WithMigrationRulesDir(rulesDir))
+			}
+			if workspace != "" {
+				modeOpts = append(modeOpts, server.WithWorkspace(workspace))
+			}
+			if schemaBundle != "" {
+				bundle, err := schema.OpenBundle(schemaBundle)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = bundle.Close() }()
+				slog.Info("serving schemas from offline bundle", "bundle", schemaBundle)
+				modeOpts = append(modeOpts, server.WithSchemaRegistry(bundle))
+			}
+
 			switch modeName {
 			case "advisory":
-				mode, err = server.NewAdvisoryMode(defaultCacheTTL)
+				mode, err = server.NewAdvisoryMode(cacheTTL, modeOpts...)
 			case "artifact":
-				mode, err = server.NewArtifactMode(defaultCacheTTL)
+				mode, err = server.NewArtifactMode(cacheTTL, modeOpts...)
 			default:
 				return fmt.Errorf("unknown mode %q: must be \"advisory\" or \"artifact\"", modeName)
 			}
@@ -63,16 +110,51 @@ func serveCmd() *cobra.Command {
 				Title:   "Gemara MCP",
 				Version: GetVersion(),
 			}, &mcp.ServerOptions{
-				Instructions: mode.Description(),
+				Instructions:      mode.Description(),
+				CompletionHandler: mode.Complete,
 			})
 
 			mode.Register(server)
 
-			return server.Run(cmd.Context(), &mcp.StdioTransport{})
+			switch transportName {
+			case "stdio":
+				return server.Run(cmd.Context(), &mcp.StdioTransport{})
+			case "http":
+				httpOpts.verifier, err = authOpts.verifier()
+				if err != nil {
+					return fmt.Errorf("configuring authentication: %w", err)
+				}
+				if httpOpts.verifier != nil {
+					authorizeToolCalls(server)
+				}
+				return serveHTTP(cmd.Context(), server, httpOpts)
+			default:
+				return fmt.Errorf("unknown transport %q: must be \"stdio\" or \"http\"", transportName)
+			}
 		},
 	}
 
 	cmd.Flags().StringVar(&modeName, "mode", "artifact", "server mode: advisory (consumer, read-only evaluation) or artifact (producer, guided artifact creation)")
+	cmd.Flags().StringVar(&schemaBundle, "schema-bundle", "", "directory or tarball created by \"bundle create\"; schemas and versions are served from it without network access")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "directory for a persistent lexicon and schema cache that survives restarts and is served stale when offline (default: in-memory only)")
+	cmd.Flags().StringVar(&rulesDir, "migration-rules-dir", "", "directory of CUE rule packs (*.cue in package migrate) applied after the built-in migrations (artifact mode)")
+	cmd.Flags().StringVar(&workspace, "workspace", "", "directory of Gemara artifacts whose metadata.id values complete the id_prefix argument of prompts (artifact mode)")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", defaultCacheTTL, "default time-to-live for cached lexicon, schemas and versions")
+	cmd.Flags().DurationVar(&cacheConfig.SchemaTTL, "schema-cache-ttl", 0, "time-to-live for built schemas (default: --cache-ttl)")
+	cmd.Flags().DurationVar(&cacheConfig.LexiconTTL, "lexicon-cache-ttl", 0, "time-to-live for the lexicon (default: --cache-ttl)")
+	cmd.Flags().DurationVar(&cacheConfig.VersionTTL, "version-cache-ttl", 0, "time-to-live for the resolved latest schema version and the version list (default: --cache-ttl)")
+	cmd.Flags().IntVar(&cacheConfig.MaxEntries, "cache-max-entries", defaultCacheMaxEntries, "maximum entries per cache; least recently used entries are evicted beyond it (0 = unbounded)")
+	cmd.Flags().BoolVar(&staleCache, "stale-while-revalidate", false, "serve expired cache entries immediately and refresh them in the background")
+	cmd.Flags().StringVar(&transportName, "transport", "stdio", "transport: stdio (local, single client) or http (streamable HTTP, shared instance)")
+	cmd.Flags().StringVar(&httpOpts.addr, "listen", defaultListenAddr, "listen address for the http transport")
+	cmd.Flags().StringVar(&httpOpts.basePath, "base-path", defaultBasePath, "URL path the MCP endpoint is served on for the http transport")
+	cmd.Flags().StringVar(&httpOpts.tls.certFile, "tls-cert", "", "PEM certificate file for TLS termination (http transport; reloaded on change)")
+	cmd.Flags().StringVar(&httpOpts.tls.keyFile, "tls-key", "", "PEM private key file for TLS termination (http transport; reloaded on change)")
+	cmd.Flags().StringVar(&httpOpts.tls.clientCAFile, "tls-client-ca", "", "PEM CA bundle; when set, clients must present a certificate signed by it (mutual TLS)")
+	cmd.Flags().StringVar(&authOpts.tokensFile, "auth-tokens-file", "", "YAML file of static bearer tokens and their scopes (http transport)")
+	cmd.Flags().StringVar(&authOpts.jwksFile, "auth-jwks-file", "", "local JWKS file used to validate JWT bearer tokens (http transport)")
+	cmd.Flags().StringVar(&authOpts.issuer, "auth-issuer", "", "required JWT \"iss\" claim")
+	cmd.Flags().StringVar(&authOpts.audience, "auth-audience", "", "required JWT \"aud\" claim")
 
 	return cmd
 }
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const tlsReloadInterval = 10 * time.Second
+
+// tlsOptions configures TLS termination for the http transport.
+type tlsOptions struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+}
+
+// enabled reports whether TLS termination is configured.
+func (o tlsOptions) enabled() bool {
+	return o.certFile != "" || o.keyFile != ""
+}
+
+// validate checks that TLS options are complete.
+func (o tlsOptions) validate() error {
+	if (o.certFile == "") != (o.keyFile == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be set together")
+	}
+	if o.clientCAFile != "" && o.certFile == "" {
+		return fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+	}
+	return nil
+}
+
+// fileStamp identifies a version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// certReloader serves the certificate, key and client CA pool from disk and
+// reloads them when the files change, so certificates can be rotated without
+// restarting the server.
+type certReloader struct {
+	opts tlsOptions
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    map[string]fileStamp
+}
+
+// newCertReloader loads the configured TLS material and returns a reloader for it.
+func newCertReloader(opts tlsOptions) (*certReloader, error) {
+	r := &certReloader{opts: opts}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// files returns the paths watched for changes.
+func (r *certReloader) files() []string {
+	files := []string{r.opts.certFile, r.opts.keyFile}
+	if r.opts.clientCAFile != "" {
+		files = append(files, r.opts.clientCAFile)
+	}
+	return files
+}
+
+// reload reads the TLS material from disk and swaps it in atomically.
+func (r *certReloader) reload() error {
+	stamps, err := statFiles(r.files())
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opts.certFile, r.opts.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.opts.clientCAFile != "" {
+		pem, err := os.ReadFile(r.opts.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file %s contains no PEM certificates", r.opts.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = pool
+	r.stamps = stamps
+	return nil
+}
+
+// changed reports whether any watched file differs from the loaded version.
+func (r *certReloader) changed() bool {
+	stamps, err := statFiles(r.files())
+	if err != nil {
+		// A file mid-rotation may be briefly missing; retry on the next tick.
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for path, stamp := range stamps {
+		if r.stamps[path] != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// watch polls the TLS files and reloads them on change until ctx is cancelled.
+// A failed reload keeps serving the previously loaded material.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				slog.Warn("failed to reload TLS certificates, keeping previous", "error", err)
+				continue
+			}
+			slog.Info("reloaded TLS certificates", "cert", r.opts.certFile)
+		}
+	}
+}
+
+// tlsConfig returns a server TLS configuration that resolves the current
+// certificate and client CA pool on every handshake. It is a single config,
+// rather than one swapped in per client, so the ALPN protocols http.Server
+// adds to it (including h2) are offered on every connection.
+func (r *certReloader) tlsConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+	}
+	if r.opts.clientCAFile != "" {
+		// Client certificates are verified in VerifyConnection rather than
+		// through ClientCAs, so a reloaded CA pool applies to new handshakes.
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = r.verifyClient
+	}
+	return cfg
+}
+
+// verifyClient verifies the client certificate chain against the current
+// client CA pool.
+func (r *certReloader) verifyClient(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("client certificate is required")
+	}
+	r.mu.RLock()
+	roots := r.clientCAs
+	r.mu.RUnlock()
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("verifying client certificate: %w", err)
+	}
+	return nil
+}
+
+func statFiles(paths []string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert is a generated certificate and its key.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, isCA bool, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+	}
+	signerCert, signerKey := tmpl, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, certPath, keyPath string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	if keyPath == "" {
+		return
+	}
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestTLSOptionsValidate(t *testing.T) {
+	assert.NoError(t, tlsOptions{}.validate())
+	assert.NoError(t, tlsOptions{certFile: "c", keyFile: "k"}.validate())
+	assert.NoError(t, tlsOptions{certFile: "c", keyFile: "k", clientCAFile: "ca"}.validate())
+	assert.Error(t, tlsOptions{certFile: "c"}.validate())
+	assert.Error(t, tlsOptions{keyFile: "k"}.validate())
+	assert.Error(t, tlsOptions{clientCAFile: "ca"}.validate())
+}
+
+func TestCertReloaderReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	opts := tlsOptions{certFile: filepath.Join(dir, "tls.crt"), keyFile: filepath.Join(dir, "tls.key")}
+
+	first := newTestCert(t, "first", nil, false, x509.ExtKeyUsageServerAuth)
+	first.write(t, opts.certFile, opts.keyFile)
+
+	r, err := newCertReloader(opts)
+	require.NoError(t, err)
+	assert.False(t, r.changed())
+
+	second := newTestCert(t, "second", nil, false, x509.ExtKeyUsageServerAuth)
+	second.write(t, opts.certFile, opts.keyFile)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(opts.certFile, future, future))
+
+	require.True(t, r.changed())
+	require.NoError(t, r.reload())
+
+	cert, err := r.tlsConfig().GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "second", leaf.Subject.CommonName)
+}
+
+func TestCertReloaderKeepsPreviousOnInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	opts := tlsOptions{certFile: filepath.Join(dir, "tls.crt"), keyFile: filepath.Join(dir, "tls.key")}
+	newTestCert(t, "server", nil, false, x509.ExtKeyUsageServerAuth).write(t, opts.certFile, opts.keyFile)
+
+	r, err := newCertReloader(opts)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(opts.certFile, []byte("not a certificate"), 0o600))
+	require.Error(t, r.reload())
+
+	cert, err := r.tlsConfig().GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "server", leaf.Subject.CommonName, "previous certificate is still served")
+}
+
+func TestCertReloaderMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil, true, x509.ExtKeyUsageAny)
+	opts := tlsOptions{
+		certFile:     filepath.Join(dir, "tls.crt"),
+		keyFile:      filepath.Join(dir, "tls.key"),
+		clientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+	newTestCert(t, "localhost", ca, false, x509.ExtKeyUsageServerAuth).write(t, opts.certFile, opts.keyFile)
+	ca.write(t, opts.clientCAFile, "")
+
+	r, err := newCertReloader(opts)
+	require.NoError(t, err)
+
+	// Served like serveHTTP does, so http.Server configures ALPN on the config.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		TLSConfig:         r.tlsConfig(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+	url := "https://" + ln.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientFor := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			ForceAttemptHTTP2: true,
+			TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				Certificates: certs,
+				MinVersion:   tls.VersionTLS12,
+			},
+		}}
+	}
+
+	_, err = clientFor().Get(url)
+	assert.Error(t, err, "client without a certificate must be rejected")
+
+	otherCA := newTestCert(t, "other-ca", nil, true, x509.ExtKeyUsageAny)
+	_, err = clientFor(newTestCert(t, "client", otherCA, false, x509.ExtKeyUsageClientAuth).tlsCertificate()).Get(url)
+	assert.Error(t, err, "client signed by another CA must be rejected")
+
+	client := newTestCert(t, "client", ca, false, x509.ExtKeyUsageClientAuth)
+	resp, err := clientFor(client.tlsCertificate()).Get(url)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor, "HTTP/2 is negotiated over ALPN")
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/gemaraproj/gemara-mcp/internal/server/report"
+	"github.com/spf13/cobra"
+)
+
+// fileValidation is the validation result of one artifact file.
+type fileValidation struct {
+	File string `json:"file"`
+	server.OutputValidateGemaraArtifact
+	// Error is set when the file could not be validated at all.
+	Error string `json:"error,omitempty"`
+}
+
+func validateCmd() *cobra.Command {
+	var (
+		input   server.InputValidateGemaraArtifact
+		format  string
+		schemas schemaFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate <files...>",
+		Short: "Validate Gemara artifacts against the Gemara CUE schema",
+		Long: `Validate Gemara artifacts against the Gemara CUE schema, as the validate_gemara_artifact tool does.
+
+Exits 0 when every artifact is valid, 1 when any artifact is invalid, and 2 when an artifact could not be validated.`,
+		Example: "gemara-mcp validate controls.yaml threats.yaml\ngemara-mcp validate --format sarif catalogs/*.yaml > gemara.sarif\ngemara-mcp validate --format junit catalogs/*.yaml > gemara-junit.xml",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkFormat(format); err != nil {
+				return err
+			}
+			quietLogs()
+			source, release, err := schemas.schemas()
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			results := validateFiles(cmd.Context(), args, input, source)
+			if err := writeValidation(cmd.OutOrStdout(), format, results); err != nil {
+				return err
+			}
+
+			failed, invalid := 0, 0
+			for _, r := range results {
+				switch {
+				case r.Error != "":
+					failed++
+				case !r.Valid:
+					invalid++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d artifact(s) could not be validated", failed, len(results))
+			}
+			if invalid > 0 {
+				return findings("%d of %d artifact(s) invalid", invalid, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&input.Definition, "definition", "", "CUE definition to validate against, e.g. #ControlCatalog (default: derived from metadata.type)")
+	cmd.Flags().StringVar(&input.Version, "version", "", "Gemara schema version to validate against (default: derived from metadata.gemara-version, else latest)")
+	cmd.Flags().StringVar(&format, "format", formatHuman, "output format: human, json, sarif or junit")
+	schemas.register(cmd)
+
+	return cmd
+}
+
+// validateFiles validates each file with ValidateGemaraArtifact.
+func validateFiles(ctx context.Context, files []string, input server.InputValidateGemaraArtifact, schemas server.SchemaSource) []fileValidation {
+	results := make([]fileValidation, 0, len(files))
+	for _, file := range files {
+		r := fileValidation{File: file}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			r.Error = err.Error()
+			results = append(results, r)
+			continue
+		}
+
+		in := input
+		in.ArtifactContent = string(content)
+		_, output, err := server.ValidateGemaraArtifact(ctx, nil, in, schemas)
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.OutputValidateGemaraArtifact = output
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func writeValidation(w io.Writer, format string, results []fileValidation) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, results)
+	case formatSARIF, formatJUnit:
+		artifacts := make([]report.Artifact, 0, len(results))
+		for _, r := range results {
+			a := report.Artifact{Path: r.File, Rule: report.RuleSchema, Diagnostics: r.Diagnostics, Errors: r.Errors}
+			if r.Error != "" {
+				a.Errors = []string{r.Error}
+			}
+			artifacts = append(artifacts, a)
+		}
+		return report.Write(w, format, GetVersion(), artifacts)
+	}
+
+	valid := 0
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(w, "%s: error: %s\n", r.File, r.Error)
+			continue
+		case r.Valid:
+			valid++
+			fmt.Fprintf(w, "%s: valid%s\n", r.File, validatedAgainst(r.OutputValidateGemaraArtifact))
+			continue
+		}
+		fmt.Fprintf(w, "%s: invalid%s\n", r.File, validatedAgainst(r.OutputValidateGemaraArtifact))
+		if len(r.Diagnostics) == 0 {
+			for _, e := range r.Errors {
+				fmt.Fprintf(w, "  %s\n", e)
+			}
+		}
+		for _, d := range r.Diagnostics {
+			fmt.Fprintf(w, "  %s\n", d)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d artifact(s) valid\n", valid, len(results))
+	return nil
+}
+
+// validatedAgainst describes the definitions and schema version an artifact
+// was validated against.
+func validatedAgainst(output server.OutputValidateGemaraArtifact) string {
+	definition := output.Definition
+	if definition == "" {
+		definition = strings.Join(output.Matches, ", ")
+	}
+	if definition == "" {
+		return fmt.Sprintf(" (schema %s)", output.Version)
+	}
+	return fmt.Sprintf(" (%s, schema %s)", definition, output.Version)
+}
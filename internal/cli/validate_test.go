@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGemaraSchema = `package gemara
+
+#ControlCatalog: {
+	metadata: {
+		id:               string
+		type:             "ControlCatalog"
+		"gemara-version": string
+	}
+	title: string
+}
+`
+
+// newTestSchemaBundle writes an offline bundle holding a minimal Gemara
+// module at v1.0.0 and returns its directory.
+func newTestSchemaBundle(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, filepath.FromSlash(server.GemaraModulePath)+"@v1.0.0")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cue.mod"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cue.mod", "module.cue"),
+		[]byte("module: \""+server.GemaraModulePath+"@v1\"\nlanguage: version: \"v0.9.0\"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.cue"), []byte(testGemaraSchema), 0o644))
+	return root
+}
+
+// runCLI runs the root command with args and returns its output and error.
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := New()
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestValidateCommand(t *testing.T) {
+	bundle := newTestSchemaBundle(t)
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("metadata:\n  id: CC\n  type: ControlCatalog\n  gemara-version: 1.0.0\ntitle: Controls\n"), 0o644))
+	require.NoError(t, os.WriteFile(invalid, []byte("metadata:\n  id: 42\n  type: ControlCatalog\n  gemara-version: 1.0.0\ntitle: Controls\n"), 0o644))
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		contains []string
+	}{
+		{
+			name:     "valid",
+			args:     []string{valid},
+			contains: []string{"valid.yaml: valid (#ControlCatalog, schema v1.0.0)", "1 of 1 artifact(s) valid"},
+		},
+		{
+			name:     "invalid",
+			args:     []string{valid, invalid},
+			wantCode: exitFindings,
+			contains: []string{"invalid.yaml: invalid (#ControlCatalog, schema v1.0.0)", "2:7: metadata.id:", "1 of 2 artifact(s) valid"},
+		},
+		{
+			name:     "unreadable file",
+			args:     []string{valid, filepath.Join(dir, "missing.yaml")},
+			wantCode: exitFailure,
+			contains: []string{"missing.yaml: error:"},
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"--format", "xml", valid},
+			wantCode: exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCLI(t, append([]string{"validate", "--schema-bundle", bundle}, tt.args...)...)
+			assert.Equal(t, tt.wantCode, ExitCode(err), "error: %v", err)
+			for _, s := range tt.contains {
+				assert.Contains(t, out, s)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		out, err := runCLI(t, "validate", "--schema-bundle", bundle, "--format", "json", valid, invalid)
+		assert.Equal(t, exitFindings, ExitCode(err))
+		var results []fileValidation
+		require.NoError(t, json.Unmarshal([]byte(out), &results))
+		require.Len(t, results, 2)
+		assert.True(t, results[0].Valid)
+		assert.Equal(t, invalid, results[1].File)
+		assert.NotEmpty(t, results[1].Diagnostics)
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		out, err := runCLI(t, "validate", "--schema-bundle", bundle, "--format", "sarif", valid, invalid)
+		assert.Equal(t, exitFindings, ExitCode(err))
+		var log struct {
+			Runs []struct {
+				Results []struct {
+					RuleID    string `json:"ruleId"`
+					Locations []struct {
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI string `json:"uri"`
+							} `json:"artifactLocation"`
+						} `json:"physicalLocation"`
+					} `json:"locations"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &log))
+		require.Len(t, log.Runs, 1)
+		require.NotEmpty(t, log.Runs[0].Results)
+		assert.Equal(t, "gemara/type-mismatch", log.Runs[0].Results[0].RuleID)
+		assert.Equal(t, filepath.ToSlash(invalid), log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		out, err := runCLI(t, "validate", "--schema-bundle", bundle, "--format", "junit", valid, invalid)
+		assert.Equal(t, exitFindings, ExitCode(err))
+		var suites struct {
+			Tests    int `xml:"tests,attr"`
+			Failures int `xml:"failures,attr"`
+			Suite    struct {
+				Cases []struct {
+					Name    string `xml:"name,attr"`
+					Failure *struct {
+						Type string `xml:"type,attr"`
+					} `xml:"failure"`
+				} `xml:"testcase"`
+			} `xml:"testsuite"`
+		}
+		require.NoError(t, xml.Unmarshal([]byte(out), &suites))
+		assert.Equal(t, 2, suites.Tests)
+		assert.Equal(t, 1, suites.Failures)
+		require.Len(t, suites.Suite.Cases, 2)
+		assert.Nil(t, suites.Suite.Cases[0].Failure)
+		assert.Equal(t, invalid, suites.Suite.Cases[1].Name)
+		require.NotNil(t, suites.Suite.Cases[1].Failure)
+		assert.Equal(t, "gemara/type-mismatch", suites.Suite.Cases[1].Failure.Type)
+	})
+}
+
+func TestMigrateCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "controls.yaml")
+	require.NoError(t, os.WriteFile(current, []byte("metadata:\n  type: ControlCatalog\n  gemara-version: v1.0.0\n"), 0o644))
+
+	tests := []struct {
+		name        string
+		args        []string
+		errContains string
+	}{
+		{name: "output required", args: []string{current}, errContains: "--output is required"},
+		{name: "missing file", args: []string{filepath.Join(dir, "missing.yaml"), "--dry-run"}, errContains: "no such file"},
+		{name: "already at target", args: []string{current, "-o", dir}, errContains: "already at target gemara-version"},
+		{name: "missing rule packs", args: []string{current, "--dry-run", "--migration-rules-dir", filepath.Join(dir, "rules")}, errContains: "loading migration rule packs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCLI(t, append([]string{"migrate", "--schema-bundle", newTestSchemaBundle(t)}, tt.args...)...)
+			require.ErrorContains(t, err, tt.errContains)
+			assert.Equal(t, exitFailure, ExitCode(err))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, exitFindings, ExitCode(findings("%d invalid", 1)))
+	assert.Equal(t, exitFailure, ExitCode(errors.New("boom")))
+}
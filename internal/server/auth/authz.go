@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ChainVerifiers returns a verifier that tries each verifier in order and
+// accepts the first successful result. Verifiers that reject the token with
+// mcpauth.ErrInvalidToken fall through to the next; any other error is returned.
+func ChainVerifiers(verifiers ...mcpauth.TokenVerifier) mcpauth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*mcpauth.TokenInfo, error) {
+		errs := make([]error, 0, len(verifiers))
+		for _, verify := range verifiers {
+			info, err := verify(ctx, token, req)
+			if err == nil {
+				return info, nil
+			}
+			if !errors.Is(err, mcpauth.ErrInvalidToken) {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// ToolScopesFunc returns the scopes, any one of which authorizes a call to the named tool.
+type ToolScopesFunc func(tool string) []string
+
+// RequireToolScopes returns MCP receiving middleware that rejects tools/call
+// requests whose bearer token lacks a scope authorizing the requested tool.
+// Requests without token info are rejected, so the middleware must only be
+// installed behind bearer-token authentication.
+func RequireToolScopes(scopesFor ToolScopesFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			var info *mcpauth.TokenInfo
+			if extra := req.GetExtra(); extra != nil {
+				info = extra.TokenInfo
+			}
+			allowed := scopesFor(call.Params.Name)
+			if info == nil || !slices.ContainsFunc(allowed, func(s string) bool { return slices.Contains(info.Scopes, s) }) {
+				user := ""
+				if info != nil {
+					user = info.UserID
+				}
+				slog.Warn("tool call denied", "tool", call.Params.Name, "user", user, "required_scopes", allowed)
+				return nil, fmt.Errorf("tool %q requires one of scopes %v", call.Params.Name, allowed)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
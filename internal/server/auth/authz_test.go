@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainVerifiers(t *testing.T) {
+	reject := func(context.Context, string, *http.Request) (*mcpauth.TokenInfo, error) {
+		return nil, fmt.Errorf("%w: rejected", mcpauth.ErrInvalidToken)
+	}
+	accept := func(context.Context, string, *http.Request) (*mcpauth.TokenInfo, error) {
+		return &mcpauth.TokenInfo{UserID: "accepted"}, nil
+	}
+	broken := func(context.Context, string, *http.Request) (*mcpauth.TokenInfo, error) {
+		return nil, errors.New("backend down")
+	}
+
+	info, err := ChainVerifiers(reject, accept)(context.Background(), "t", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", info.UserID)
+
+	_, err = ChainVerifiers(reject, reject)(context.Background(), "t", nil)
+	assert.ErrorIs(t, err, mcpauth.ErrInvalidToken)
+
+	_, err = ChainVerifiers(broken, accept)(context.Background(), "t", nil)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, mcpauth.ErrInvalidToken, "non-token errors must not fall through")
+}
+
+func TestRequireToolScopes(t *testing.T) {
+	scopesFor := func(tool string) []string {
+		if tool == "read_only" {
+			return []string{"advisory", "artifact"}
+		}
+		return []string{"artifact"}
+	}
+	next := func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+	handler := RequireToolScopes(scopesFor)(next)
+
+	callTool := func(tool string, info *mcpauth.TokenInfo) error {
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool},
+			Extra:  &mcp.RequestExtra{TokenInfo: info},
+		}
+		_, err := handler(context.Background(), "tools/call", req)
+		return err
+	}
+	advisory := &mcpauth.TokenInfo{Scopes: []string{"advisory"}, Expiration: time.Now().Add(time.Hour)}
+	artifact := &mcpauth.TokenInfo{Scopes: []string{"artifact"}, Expiration: time.Now().Add(time.Hour)}
+
+	assert.NoError(t, callTool("read_only", advisory))
+	assert.NoError(t, callTool("read_only", artifact))
+	assert.NoError(t, callTool("write", artifact))
+
+	err := callTool("write", advisory)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires one of scopes")
+
+	assert.Error(t, callTool("read_only", nil), "missing token info must be rejected")
+
+	_, err = handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+	assert.NoError(t, err, "non tool-call methods pass through")
+}
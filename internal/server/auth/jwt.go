@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// allowedSignatureAlgorithms lists the asymmetric algorithms accepted for JWTs.
+// Symmetric algorithms are excluded because the keys come from a public JWKS.
+var allowedSignatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTOptions configures the claims a JWTVerifier requires.
+type JWTOptions struct {
+	// Issuer, if set, must match the "iss" claim.
+	Issuer string
+	// Audience, if set, must appear in the "aud" claim.
+	Audience string
+}
+
+// JWTVerifier validates signed JWTs against keys from a local JWKS file.
+type JWTVerifier struct {
+	keys *jose.JSONWebKeySet
+	opts JWTOptions
+	now  func() time.Time
+}
+
+// scopeClaims holds the scope claims used by common OIDC providers:
+// a space-delimited "scope" string or an "scp" array.
+type scopeClaims struct {
+	Scope string   `json:"scope,omitempty"`
+	Scp   []string `json:"scp,omitempty"`
+}
+
+// LoadJWTVerifier reads a JWKS file and returns a verifier for tokens signed by its keys.
+func LoadJWTVerifier(jwksPath string, opts JWTOptions) (*JWTVerifier, error) {
+	data, err := os.ReadFile(jwksPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS file: %w", err)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parsing JWKS file %s: %w", jwksPath, err)
+	}
+	return NewJWTVerifier(&keys, opts)
+}
+
+// NewJWTVerifier creates a verifier for tokens signed by keys in the given set.
+func NewJWTVerifier(keys *jose.JSONWebKeySet, opts JWTOptions) (*JWTVerifier, error) {
+	if keys == nil || len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no keys")
+	}
+	for _, k := range keys.Keys {
+		if k.KeyID == "" {
+			return nil, fmt.Errorf("JWKS key without \"kid\" cannot be selected")
+		}
+		if !k.IsPublic() {
+			return nil, fmt.Errorf("JWKS key %q is not a public key", k.KeyID)
+		}
+	}
+	return &JWTVerifier{keys: keys, opts: opts, now: time.Now}, nil
+}
+
+// Verify implements mcpauth.TokenVerifier.
+func (v *JWTVerifier) Verify(_ context.Context, token string, _ *http.Request) (*mcpauth.TokenInfo, error) {
+	parsed, err := jwt.ParseSigned(token, allowedSignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT: %v", mcpauth.ErrInvalidToken, err)
+	}
+
+	var (
+		claims jwt.Claims
+		scopes scopeClaims
+	)
+	if err := parsed.Claims(v.keys, &claims, &scopes); err != nil {
+		return nil, fmt.Errorf("%w: JWT signature: %v", mcpauth.ErrInvalidToken, err)
+	}
+
+	expected := jwt.Expected{Issuer: v.opts.Issuer, Time: v.now()}
+	if v.opts.Audience != "" {
+		expected.AnyAudience = jwt.Audience{v.opts.Audience}
+	}
+	if err := claims.Validate(expected); err != nil {
+		return nil, fmt.Errorf("%w: JWT claims: %v", mcpauth.ErrInvalidToken, err)
+	}
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: JWT missing \"exp\" claim", mcpauth.ErrInvalidToken)
+	}
+
+	return &mcpauth.TokenInfo{
+		Scopes:     append(strings.Fields(scopes.Scope), scopes.Scp...),
+		Expiration: claims.Expiry.Time(),
+		UserID:     claims.Subject,
+	}, nil
+}
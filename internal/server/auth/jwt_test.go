@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyID = "test-key"
+
+type testClaims struct {
+	jwt.Claims
+	Scope string   `json:"scope,omitempty"`
+	Scp   []string `json:"scp,omitempty"`
+}
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, *jose.JSONWebKeySet) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &key.PublicKey,
+		KeyID:     testKeyID,
+		Algorithm: string(jose.ES256),
+		Use:       "sig",
+	}}}
+	return key, jwks
+}
+
+func signToken(t *testing.T, key *ecdsa.PrivateKey, kid string, claims testClaims) string {
+	t.Helper()
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), kid),
+	)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTVerifier(t *testing.T) {
+	key, jwks := newTestKey(t)
+	otherKey, _ := newTestKey(t)
+	now := time.Now()
+
+	v, err := NewJWTVerifier(jwks, JWTOptions{Issuer: "https://idp.example.com", Audience: "gemara-mcp"})
+	require.NoError(t, err)
+
+	valid := jwt.Claims{
+		Issuer:   "https://idp.example.com",
+		Subject:  "alice",
+		Audience: jwt.Audience{"gemara-mcp"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+
+	tests := []struct {
+		name       string
+		token      func() string
+		wantScopes []string
+		wantErr    bool
+	}{
+		{
+			name: "valid token with scope string",
+			token: func() string {
+				return signToken(t, key, testKeyID, testClaims{Claims: valid, Scope: "advisory artifact"})
+			},
+			wantScopes: []string{"advisory", "artifact"},
+		},
+		{
+			name: "valid token with scp array",
+			token: func() string {
+				return signToken(t, key, testKeyID, testClaims{Claims: valid, Scp: []string{"advisory"}})
+			},
+			wantScopes: []string{"advisory"},
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				c := valid
+				c.Issuer = "https://evil.example.com"
+				return signToken(t, key, testKeyID, testClaims{Claims: c})
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := valid
+				c.Audience = jwt.Audience{"other"}
+				return signToken(t, key, testKeyID, testClaims{Claims: c})
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := valid
+				c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+				return signToken(t, key, testKeyID, testClaims{Claims: c})
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing expiry",
+			token: func() string {
+				c := valid
+				c.Expiry = nil
+				return signToken(t, key, testKeyID, testClaims{Claims: c})
+			},
+			wantErr: true,
+		},
+		{
+			name:    "signed by unknown key",
+			token:   func() string { return signToken(t, otherKey, testKeyID, testClaims{Claims: valid}) },
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			token:   func() string { return signToken(t, key, "other-kid", testClaims{Claims: valid}) },
+			wantErr: true,
+		},
+		{
+			name:    "not a JWT",
+			token:   func() string { return "plain-secret" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := v.Verify(context.Background(), tt.token(), nil)
+			if tt.wantErr {
+				require.ErrorIs(t, err, mcpauth.ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alice", info.UserID)
+			assert.Equal(t, tt.wantScopes, info.Scopes)
+			assert.WithinDuration(t, now.Add(time.Hour), info.Expiration, time.Second)
+		})
+	}
+}
+
+func TestLoadJWTVerifier(t *testing.T) {
+	_, jwks := newTestKey(t)
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	_, err = LoadJWTVerifier(path, JWTOptions{})
+	require.NoError(t, err)
+
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	require.NoError(t, os.WriteFile(empty, []byte(`{"keys":[]}`), 0o600))
+	_, err = LoadJWTVerifier(empty, JWTOptions{})
+	assert.ErrorContains(t, err, "no keys")
+}
+
+func TestNewJWTVerifierRejectsPrivateKeys(t *testing.T) {
+	key, _ := newTestKey(t)
+	_, err := NewJWTVerifier(&jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key, KeyID: testKeyID}}}, JWTOptions{})
+	assert.ErrorContains(t, err, "not a public key")
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// staticTokenLifetime is the expiration reported for static tokens. Static tokens
+// do not expire, but the MCP SDK rejects TokenInfo without an expiration.
+const staticTokenLifetime = 1 * time.Hour
+
+// TokenFile is the on-disk format for static bearer tokens.
+type TokenFile struct {
+	Tokens []StaticToken `yaml:"tokens"`
+}
+
+// StaticToken is a named bearer token and the scopes it grants.
+// Exactly one of Token (plaintext) or SHA256 (hex digest of the token) must be set.
+type StaticToken struct {
+	Name   string   `yaml:"name"`
+	Token  string   `yaml:"token,omitempty"`
+	SHA256 string   `yaml:"sha256,omitempty"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// StaticTokenVerifier authenticates bearer tokens against a fixed set of tokens.
+type StaticTokenVerifier struct {
+	tokens []staticEntry
+}
+
+type staticEntry struct {
+	name   string
+	digest []byte
+	scopes []string
+}
+
+// LoadStaticTokens reads a YAML token file and returns a verifier for its tokens.
+func LoadStaticTokens(path string) (*StaticTokenVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading token file: %w", err)
+	}
+	var file TokenFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing token file %s: %w", path, err)
+	}
+	return NewStaticTokenVerifier(file.Tokens)
+}
+
+// NewStaticTokenVerifier creates a verifier from the given tokens.
+func NewStaticTokenVerifier(tokens []StaticToken) (*StaticTokenVerifier, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens configured")
+	}
+	v := &StaticTokenVerifier{}
+	seen := make(map[string]bool, len(tokens))
+	for i, t := range tokens {
+		if t.Name == "" {
+			return nil, fmt.Errorf("token %d: name is required", i)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("token %q: duplicate name", t.Name)
+		}
+		seen[t.Name] = true
+
+		var digest []byte
+		switch {
+		case t.Token != "" && t.SHA256 != "":
+			return nil, fmt.Errorf("token %q: set only one of token or sha256", t.Name)
+		case t.Token != "":
+			sum := sha256.Sum256([]byte(t.Token))
+			digest = sum[:]
+		case t.SHA256 != "":
+			decoded, err := hex.DecodeString(strings.TrimSpace(t.SHA256))
+			if err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("token %q: sha256 must be a hex-encoded SHA-256 digest", t.Name)
+			}
+			digest = decoded
+		default:
+			return nil, fmt.Errorf("token %q: one of token or sha256 is required", t.Name)
+		}
+
+		v.tokens = append(v.tokens, staticEntry{name: t.Name, digest: digest, scopes: t.Scopes})
+	}
+	return v, nil
+}
+
+// Verify implements mcpauth.TokenVerifier. Every configured token is compared in
+// constant time so response timing does not reveal which token was close.
+func (v *StaticTokenVerifier) Verify(_ context.Context, token string, _ *http.Request) (*mcpauth.TokenInfo, error) {
+	sum := sha256.Sum256([]byte(token))
+	var match *staticEntry
+	for i := range v.tokens {
+		if subtle.ConstantTimeCompare(sum[:], v.tokens[i].digest) == 1 {
+			match = &v.tokens[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: unknown bearer token", mcpauth.ErrInvalidToken)
+	}
+	return &mcpauth.TokenInfo{
+		Scopes:     match.scopes,
+		Expiration: time.Now().Add(staticTokenLifetime),
+		UserID:     match.name,
+	}, nil
+}
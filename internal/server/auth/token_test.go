@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticTokenVerifier(t *testing.T) {
+	digest := sha256.Sum256([]byte("hashed-secret"))
+	v, err := NewStaticTokenVerifier([]StaticToken{
+		{Name: "ci", Token: "plain-secret", Scopes: []string{"advisory"}},
+		{Name: "authors", SHA256: hex.EncodeToString(digest[:]), Scopes: []string{"artifact"}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantUser   string
+		wantScopes []string
+		wantErr    bool
+	}{
+		{name: "plaintext token", token: "plain-secret", wantUser: "ci", wantScopes: []string{"advisory"}},
+		{name: "hashed token", token: "hashed-secret", wantUser: "authors", wantScopes: []string{"artifact"}},
+		{name: "unknown token", token: "nope", wantErr: true},
+		{name: "empty token", token: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := v.Verify(context.Background(), tt.token, nil)
+			if tt.wantErr {
+				require.ErrorIs(t, err, mcpauth.ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUser, info.UserID)
+			assert.Equal(t, tt.wantScopes, info.Scopes)
+			assert.False(t, info.Expiration.IsZero(), "MCP SDK requires an expiration")
+		})
+	}
+}
+
+func TestNewStaticTokenVerifierRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokens      []StaticToken
+		errContains string
+	}{
+		{name: "no tokens", errContains: "no tokens"},
+		{name: "missing name", tokens: []StaticToken{{Token: "x"}}, errContains: "name is required"},
+		{name: "duplicate name", tokens: []StaticToken{{Name: "a", Token: "x"}, {Name: "a", Token: "y"}}, errContains: "duplicate"},
+		{name: "missing secret", tokens: []StaticToken{{Name: "a"}}, errContains: "one of token or sha256"},
+		{name: "both secrets", tokens: []StaticToken{{Name: "a", Token: "x", SHA256: "00"}}, errContains: "only one"},
+		{name: "bad digest", tokens: []StaticToken{{Name: "a", SHA256: "abc"}}, errContains: "hex-encoded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStaticTokenVerifier(tt.tokens)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestLoadStaticTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`tokens:
+  - name: ci
+    token: plain-secret
+    scopes: [advisory]
+`), 0o600))
+
+	v, err := LoadStaticTokens(path)
+	require.NoError(t, err)
+	info, err := v.Verify(context.Background(), "plain-secret", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", info.UserID)
+
+	_, err = LoadStaticTokens(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletionValues is the most values a completion result may carry.
+const maxCompletionValues = 100
+
+// Bounds on the workspace scan behind id_prefix completions.
+const (
+	// workspacePrefixTTL is how long a scan's prefixes are reused.
+	workspacePrefixTTL = 30 * time.Second
+	// maxWorkspaceFiles is the most YAML files a scan reads.
+	maxWorkspaceFiles = 10000
+	// maxWorkspaceFileSize is the largest YAML file a scan reads, in bytes.
+	maxWorkspaceFileSize = 1 << 20
+)
+
+// Complete answers completion/complete requests. Completions are keyed by
+// argument name, so every prompt or resource template argument of that name
+// completes the same way: version from the published Gemara schema versions,
+// and definition from the top-level definitions of the schema version given
+// earlier in the request context (default: latest). Other arguments have no
+// completions. Lookup failures yield no completions rather than an error.
+func (a *AdvisoryMode) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	if req.Params == nil {
+		return completionResult(nil, ""), nil
+	}
+	arg := req.Params.Argument
+
+	var values []string
+	switch arg.Name {
+	case "version":
+		values = a.completeVersions(ctx)
+	case "definition":
+		values = a.completeDefinitions(ctx, contextArgument(req.Params, "version"))
+	}
+	return completionResult(values, arg.Value), nil
+}
+
+// Complete extends AdvisoryMode.Complete with id_prefix completions from the
+// metadata.id of the artifacts in the configured workspace.
+func (a *ArtifactMode) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	if req.Params != nil && req.Params.Argument.Name == "id_prefix" {
+		return completionResult(a.workspacePrefixes(), req.Params.Argument.Value), nil
+	}
+	return a.AdvisoryMode.Complete(ctx, req)
+}
+
+// completeVersions returns "latest" followed by the published schema
+// versions, newest first.
+func (a *AdvisoryMode) completeVersions(ctx context.Context) []string {
+	versions, err := a.schemaVersions(ctx)
+	if err != nil {
+		slog.Warn("failed to list schema versions for completion", "error", err)
+		return []string{defaultSchemaVersion}
+	}
+	slices.Reverse(versions)
+	return append([]string{defaultSchemaVersion}, versions...)
+}
+
+// completeDefinitions returns the top-level definitions of the schema at
+// version.
+func (a *AdvisoryMode) completeDefinitions(ctx context.Context, version string) []string {
+	if version == "" {
+		version = defaultSchemaVersion
+	}
+	if err := fetcher.ValidateVersion(version); err != nil {
+		return nil
+	}
+	val, _, err := a.schemaFetcher(version).Fetch(ctx, false)
+	if err != nil {
+		slog.Warn("failed to fetch schema for completion", "version", version, "error", err)
+		return nil
+	}
+	definitions, err := schema.Definitions(val)
+	if err != nil {
+		slog.Warn("failed to list schema definitions for completion", "version", version, "error", err)
+		return nil
+	}
+	return definitions
+}
+
+// contextArgument returns a previously resolved argument from the request
+// context, or "" when the client sent none.
+func contextArgument(params *mcp.CompleteParams, name string) string {
+	if params.Context == nil {
+		return ""
+	}
+	return params.Context.Arguments[name]
+}
+
+// completionResult returns the values that start with prefix, ignoring case
+// and a leading '#', capped at maxCompletionValues.
+func completionResult(values []string, prefix string) *mcp.CompleteResult {
+	prefix = strings.ToLower(strings.TrimPrefix(prefix, "#"))
+	matches := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(strings.TrimPrefix(v, "#")), prefix) {
+			matches = append(matches, v)
+		}
+	}
+	total := len(matches)
+	if total > maxCompletionValues {
+		matches = matches[:maxCompletionValues]
+	}
+	return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{
+		Values:  matches,
+		Total:   total,
+		HasMore: total > maxCompletionValues,
+	}}
+}
+
+// workspacePrefixes returns the id_prefix completions for the configured
+// workspace, rescanning it at most once per workspacePrefixTTL.
+func (a *ArtifactMode) workspacePrefixes() []string {
+	if a.workspace == "" {
+		return nil
+	}
+	if prefixes, _, ok := a.prefixCache.Get(a.workspace); ok {
+		return prefixes
+	}
+	prefixes := scanPrefixes(os.DirFS(a.workspace))
+	a.prefixCache.Set(a.workspace, prefixes, "workspace")
+	return prefixes
+}
+
+// scanPrefixes returns the metadata.id of every Gemara artifact in the YAML
+// files of fsys that is a valid id_prefix, sorted and deduplicated. Hidden
+// directories, unreadable entries, files larger than maxWorkspaceFileSize and
+// files that are not artifacts are skipped. The scan stops after
+// maxWorkspaceFiles YAML files.
+func scanPrefixes(fsys fs.FS) []string {
+	prefixes := make(map[string]bool)
+	files := 0
+	_ = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			slog.Debug("skipping unreadable workspace entry", "path", path, "error", err)
+			if d != nil && d.IsDir() && path != "." {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		if files++; files > maxWorkspaceFiles {
+			slog.Warn("workspace scan stopped at file limit", "limit", maxWorkspaceFiles)
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxWorkspaceFileSize {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil
+		}
+		meta, err := readArtifactMetadata(string(content))
+		if err != nil || meta.Metadata.Type == "" {
+			return nil
+		}
+		if validIDPrefixPattern.MatchString(meta.Metadata.ID) {
+			prefixes[meta.Metadata.ID] = true
+		}
+		return nil
+	})
+	return slices.Sorted(maps.Keys(prefixes))
+}
@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompletionBundle returns an offline schema bundle holding two versions
+// of a minimal Gemara module.
+func newCompletionBundle(t *testing.T) *schema.Bundle {
+	t.Helper()
+	root := t.TempDir()
+	for version, defs := range map[string]string{
+		"v1.0.0": "#ControlCatalog: {title: string}\n",
+		"v1.1.0": "#ControlCatalog: {title: string}\n#Policy: {title: string}\n",
+	} {
+		dir := filepath.Join(root, filepath.FromSlash(GemaraModulePath)+"@"+version)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "cue.mod"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cue.mod", "module.cue"),
+			[]byte("module: \""+GemaraModulePath+"@v1\"\nlanguage: version: \"v0.9.0\"\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.cue"), []byte("package gemara\n"+defs), 0o644))
+	}
+	b, err := schema.OpenBundle(root)
+	require.NoError(t, err)
+	return b
+}
+
+func completeRequest(name, value string, context map[string]string) *mcp.CompleteRequest {
+	params := &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "control_catalog"},
+		Argument: mcp.CompleteParamsArgument{Name: name, Value: value},
+	}
+	if context != nil {
+		params.Context = &mcp.CompleteContext{Arguments: context}
+	}
+	return &mcp.CompleteRequest{Params: params}
+}
+
+func TestArtifactModeComplete(t *testing.T) {
+	workspace := t.TempDir()
+	artifacts := map[string]string{
+		"catalog.yaml":         "metadata:\n  id: ACME.WEB\n  type: ControlCatalog\n",
+		"nested/policy.yml":    "metadata:\n  id: ACME.POL\n  type: Policy\n",
+		"nested/dup.yaml":      "metadata:\n  id: ACME.WEB\n  type: ControlCatalog\n",
+		"invalid-id.yaml":      "metadata:\n  id: acme web\n  type: ControlCatalog\n",
+		"untyped.yaml":         "metadata:\n  id: OTHER\n",
+		"notes.md":             "metadata:\n  id: NOTES\n  type: Policy\n",
+		".hidden/threats.yaml": "metadata:\n  id: HIDDEN\n  type: ThreatCatalog\n",
+	}
+	for name, content := range artifacts {
+		path := filepath.Join(workspace, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	mode, err := NewArtifactMode(1*time.Hour, WithSchemaRegistry(newCompletionBundle(t)), WithWorkspace(workspace))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		arg     string
+		value   string
+		context map[string]string
+		want    []string
+	}{
+		{name: "all versions newest first", arg: "version", want: []string{"latest", "v1.1.0", "v1.0.0"}},
+		{name: "cached versions keep their order", arg: "version", want: []string{"latest", "v1.1.0", "v1.0.0"}},
+		{name: "version prefix", arg: "version", value: "v1.1", want: []string{"v1.1.0"}},
+		{name: "latest definitions", arg: "definition", want: []string{"#ControlCatalog", "#Policy"}},
+		{name: "definitions of context version", arg: "definition", context: map[string]string{"version": "v1.0.0"}, want: []string{"#ControlCatalog"}},
+		{name: "definition prefix without hash", arg: "definition", value: "pol", want: []string{"#Policy"}},
+		{name: "definition prefix with hash", arg: "definition", value: "#Con", want: []string{"#ControlCatalog"}},
+		{name: "invalid context version", arg: "definition", context: map[string]string{"version": "../v1"}, want: []string{}},
+		{name: "workspace id prefixes", arg: "id_prefix", want: []string{"ACME.POL", "ACME.WEB"}},
+		{name: "id prefix filter", arg: "id_prefix", value: "acme.w", want: []string{"ACME.WEB"}},
+		{name: "unknown argument", arg: "component", value: "web", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mode.Complete(context.Background(), completeRequest(tt.arg, tt.value, tt.context))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Completion.Values)
+			assert.Equal(t, len(tt.want), result.Completion.Total)
+			assert.False(t, result.Completion.HasMore)
+		})
+	}
+}
+
+func TestArtifactModeCompleteWithoutWorkspace(t *testing.T) {
+	mode, err := NewArtifactMode(1*time.Hour, WithSchemaRegistry(newCompletionBundle(t)))
+	require.NoError(t, err)
+
+	result, err := mode.Complete(context.Background(), completeRequest("id_prefix", "", nil))
+	require.NoError(t, err)
+	assert.Empty(t, result.Completion.Values)
+}
+
+func TestArtifactModeCompleteReusesWorkspaceScan(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "catalog.yaml"),
+		[]byte("metadata:\n  id: ACME.WEB\n  type: ControlCatalog\n"), 0o644))
+	mode, err := NewArtifactMode(1*time.Hour, WithSchemaRegistry(newCompletionBundle(t)), WithWorkspace(workspace))
+	require.NoError(t, err)
+
+	result, err := mode.Complete(context.Background(), completeRequest("id_prefix", "", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACME.WEB"}, result.Completion.Values)
+
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "policy.yaml"),
+		[]byte("metadata:\n  id: ACME.POL\n  type: Policy\n"), 0o644))
+	result, err = mode.Complete(context.Background(), completeRequest("id_prefix", "", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACME.WEB"}, result.Completion.Values, "scan should be cached")
+}
+
+// unreadableFS fails to list dir and to read file.
+type unreadableFS struct {
+	fstest.MapFS
+	dir, file string
+}
+
+func (f unreadableFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == f.dir {
+		return nil, fs.ErrPermission
+	}
+	return f.MapFS.ReadDir(name)
+}
+
+func (f unreadableFS) ReadFile(name string) ([]byte, error) {
+	if name == f.file {
+		return nil, fs.ErrPermission
+	}
+	return f.MapFS.ReadFile(name)
+}
+
+func TestScanPrefixesSkipsUnreadableEntries(t *testing.T) {
+	artifact := func(id string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte("metadata:\n  id: " + id + "\n  type: ControlCatalog\n")}
+	}
+	fsys := unreadableFS{
+		MapFS: fstest.MapFS{
+			"a/catalog.yaml":      artifact("ACME.A"),
+			"locked/catalog.yaml": artifact("ACME.LOCKED"),
+			"secret.yaml":         artifact("ACME.SECRET"),
+			"z/catalog.yaml":      artifact("ACME.Z"),
+			"large.yaml": {Data: append([]byte("metadata:\n  id: ACME.LARGE\n  type: ControlCatalog\n"),
+				make([]byte, maxWorkspaceFileSize)...)},
+		},
+		dir:  "locked",
+		file: "secret.yaml",
+	}
+
+	assert.Equal(t, []string{"ACME.A", "ACME.Z"}, scanPrefixes(fsys))
+}
+
+func TestCompletionResultLimit(t *testing.T) {
+	values := make([]string, maxCompletionValues+5)
+	for i := range values {
+		values[i] = fmt.Sprintf("ID%03d", i)
+	}
+
+	result := completionResult(values, "")
+	assert.Len(t, result.Completion.Values, maxCompletionValues)
+	assert.Equal(t, maxCompletionValues+5, result.Completion.Total)
+	assert.True(t, result.Completion.HasMore)
+
+	result = completionResult(values, "ID10")
+	assert.Equal(t, []string{"ID100", "ID101", "ID102", "ID103", "ID104"}, result.Completion.Values)
+	assert.False(t, result.Completion.HasMore)
+}
+
+func TestCompletionOverSession(t *testing.T) {
+	mode, err := NewAdvisoryMode(1*time.Hour, WithSchemaRegistry(newCompletionBundle(t)))
+	require.NoError(t, err)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.0"}, &mcp.ServerOptions{
+		CompletionHandler: mode.Complete,
+	})
+	mode.Register(server)
+	session := connectSession(t, server)
+
+	result, err := session.Complete(context.Background(), &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/resource", URI: SchemaDocsResourceURITemplate},
+		Argument: mcp.CompleteParamsArgument{Name: "version", Value: "v1.0"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0"}, result.Completion.Values)
+
+	result, err = session.Complete(context.Background(), &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/resource", URI: SchemaDocsResourceURITemplate},
+		Argument: mcp.CompleteParamsArgument{Name: "definition", Value: "#"},
+		Context:  &mcp.CompleteContext{Arguments: map[string]string{"version": "v1.1.0"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#ControlCatalog", "#Policy"}, result.Completion.Values)
+
+	read, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{
+		URI: "gemara://schema/definitions?version=v1.1.0&definition=Policy",
+	})
+	require.NoError(t, err)
+	require.Len(t, read.Contents, 1)
+	assert.Contains(t, read.Contents[0].Text, "#Policy:")
+	assert.NotContains(t, read.Contents[0].Text, "#ControlCatalog")
+
+	_, err = session.ReadResource(context.Background(), &mcp.ReadResourceParams{
+		URI: "gemara://schema/definitions?version=v1.0.0&definition=%23Policy",
+	})
+	assert.ErrorContains(t, err, "schema has no definition #Policy")
+}
@@ -9,7 +9,7 @@ import (
 const (
 	LexiconResourceURI            = "gemara://lexicon"
 	SchemaDocsResourceURI         = "gemara://schema/definitions"
-	SchemaDocsResourceURITemplate = "gemara://schema/definitions{?version}"
+	SchemaDocsResourceURITemplate = "gemara://schema/definitions{?version,definition}"
 )
 
 // DefaultGemaraVersion is derived from the go-gemara SDK's supported schema version.
@@ -3,58 +3,213 @@
 package fetcher
 
 import (
+	"container/list"
 	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
-// Cache is a generic, thread-safe TTL cache keyed by string.
+// Cache is a generic, thread-safe TTL cache keyed by string. A cache created
+// with NewDiskCache also persists entries to disk so they survive restarts.
+//
+// Inserting a new key purges expired entries from memory; when the cache is
+// bounded with WithMaxEntries, the least recently used entries are then
+// evicted to stay within the bound.
 type Cache[T any] struct {
-	mu    sync.RWMutex
-	items map[string]cacheItem[T]
-	ttl   time.Duration
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List // front is most recently used; values are *cacheItem[T]
+	ttl        time.Duration
+	maxEntries int
+	disk       *diskStore[T]
+
+	// inflight coalesces concurrent fetches for the same key across every
+	// CachedFetcher sharing this cache.
+	inflight singleflight.Group
 }
 
 type cacheItem[T any] struct {
+	key       string
 	value     T
 	source    string
 	cacheTime time.Time
 }
 
+// CacheOption configures optional Cache behavior.
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	maxEntries int
+}
+
+// WithMaxEntries bounds the number of entries held in memory. Zero or a
+// negative value means unbounded.
+func WithMaxEntries(n int) CacheOption {
+	return func(c *cacheConfig) {
+		c.maxEntries = n
+	}
+}
+
 // NewCache creates a new cache with the specified TTL.
-func NewCache[T any](ttl time.Duration) *Cache[T] {
+func NewCache[T any](ttl time.Duration, opts ...CacheOption) *Cache[T] {
+	var cfg cacheConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	return &Cache[T]{
-		items: make(map[string]cacheItem[T]),
-		ttl:   ttl,
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		ttl:        ttl,
+		maxEntries: cfg.maxEntries,
 	}
 }
 
+// NewDiskCache creates a cache with the specified TTL that persists entries
+// under dir using codec. Entries read back from disk keep their original
+// fetch time, so the TTL applies across restarts. Entries evicted from a
+// bounded cache are removed from disk as well.
+func NewDiskCache[T any](ttl time.Duration, dir string, codec Codec[T], opts ...CacheOption) (*Cache[T], error) {
+	disk, err := newDiskStore(dir, codec)
+	if err != nil {
+		return nil, err
+	}
+	c := NewCache[T](ttl, opts...)
+	c.disk = disk
+	return c, nil
+}
+
 // Get retrieves a cached value if available and not expired.
 func (c *Cache[T]) Get(key string) (T, string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	item, found := c.items[key]
-	if !found {
+	item, found := c.lookup(key)
+	if !found || c.expired(item) {
 		var zero T
 		return zero, "", false
 	}
+	return item.value, item.source, true
+}
 
-	if time.Since(item.cacheTime) >= c.ttl {
+// GetStale retrieves a cached value regardless of its age. It lets callers
+// fall back to an expired entry when the source is unreachable.
+func (c *Cache[T]) GetStale(key string) (T, string, bool) {
+	item, found := c.lookup(key)
+	if !found {
 		var zero T
 		return zero, "", false
 	}
-
 	return item.value, item.source, true
 }
 
 // Set stores a value in the cache.
 func (c *Cache[T]) Set(key string, value T, source string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.items[key] = cacheItem[T]{
+	item := &cacheItem[T]{
+		key:       key,
 		value:     value,
 		source:    source,
 		cacheTime: time.Now(),
 	}
+
+	c.mu.Lock()
+	evicted := c.insert(item)
+	c.mu.Unlock()
+
+	if c.disk != nil {
+		c.disk.store(key, *item)
+		for _, k := range evicted {
+			c.disk.remove(k)
+		}
+	}
+}
+
+// Len returns the number of entries held in memory.
+func (c *Cache[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Purge removes expired entries from memory and returns how many were
+// removed. Persisted entries stay on disk as stale fallbacks.
+func (c *Cache[T]) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.purgeExpired()
+}
+
+// lookup returns the entry for key from memory, loading it from disk on a
+// miss, and marks it as recently used.
+func (c *Cache[T]) lookup(key string) (cacheItem[T], bool) {
+	c.mu.Lock()
+	if el, found := c.items[key]; found {
+		c.lru.MoveToFront(el)
+		item := *el.Value.(*cacheItem[T])
+		c.mu.Unlock()
+		return item, true
+	}
+	c.mu.Unlock()
+	if c.disk == nil {
+		return cacheItem[T]{}, false
+	}
+
+	item, found := c.disk.load(key)
+	if !found {
+		return item, false
+	}
+
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		// A concurrent Set won the race; prefer the newer entry.
+		current := *el.Value.(*cacheItem[T])
+		c.mu.Unlock()
+		return current, true
+	}
+	evicted := c.insert(&item)
+	c.mu.Unlock()
+
+	for _, k := range evicted {
+		c.disk.remove(k)
+	}
+	return item, true
+}
+
+// insert adds or replaces an entry and returns the keys evicted to stay
+// within maxEntries. The caller must hold c.mu.
+func (c *Cache[T]) insert(item *cacheItem[T]) []string {
+	if el, found := c.items[item.key]; found {
+		el.Value = item
+		c.lru.MoveToFront(el)
+		return nil
+	}
+	c.purgeExpired()
+	c.items[item.key] = c.lru.PushFront(item)
+
+	var evicted []string
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		key := oldest.Value.(*cacheItem[T]).key
+		c.lru.Remove(oldest)
+		delete(c.items, key)
+		evicted = append(evicted, key)
+	}
+	return evicted
+}
+
+// purgeExpired removes expired entries from memory. The caller must hold c.mu.
+func (c *Cache[T]) purgeExpired() int {
+	removed := 0
+	for el := c.lru.Back(); el != nil; {
+		prev := el.Prev()
+		item := el.Value.(*cacheItem[T])
+		if c.expired(*item) {
+			c.lru.Remove(el)
+			delete(c.items, item.key)
+			removed++
+		}
+		el = prev
+	}
+	return removed
+}
+
+func (c *Cache[T]) expired(item cacheItem[T]) bool {
+	return time.Since(item.cacheTime) >= c.ttl
 }
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package fetcher
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache[string](time.Hour, WithMaxEntries(2))
+	c.Set("v1", "one", "src")
+	c.Set("v2", "two", "src")
+
+	_, _, found := c.Get("v1")
+	require.True(t, found, "touch v1 so v2 becomes least recently used")
+
+	c.Set("v3", "three", "src")
+	assert.Equal(t, 2, c.Len())
+
+	_, _, found = c.Get("v2")
+	assert.False(t, found, "least recently used entry is evicted")
+	for _, key := range []string{"v1", "v3"} {
+		_, _, found = c.Get(key)
+		assert.True(t, found, key)
+	}
+}
+
+func TestCacheReplacingKeyDoesNotEvict(t *testing.T) {
+	c := NewCache[string](time.Hour, WithMaxEntries(2))
+	c.Set("v1", "one", "src")
+	c.Set("v2", "two", "src")
+	c.Set("v1", "uno", "src")
+
+	assert.Equal(t, 2, c.Len())
+	val, _, found := c.Get("v1")
+	require.True(t, found)
+	assert.Equal(t, "uno", val)
+}
+
+func TestCachePurgesExpiredEntries(t *testing.T) {
+	c := NewCache[string](20 * time.Millisecond)
+	c.Set("v1", "one", "src")
+	c.Set("v2", "two", "src")
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Equal(t, 2, c.Purge())
+	assert.Equal(t, 0, c.Len())
+
+	c.Set("v3", "three", "src")
+	time.Sleep(30 * time.Millisecond)
+	c.Set("v4", "four", "src")
+	assert.Equal(t, 1, c.Len(), "inserting a new key purges expired entries")
+}
+
+func TestDiskCacheEvictionRemovesFiles(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache[string](time.Hour, dir, StringCodec, WithMaxEntries(1))
+	require.NoError(t, err)
+	c.Set("v1", "one", "src")
+	c.Set("v2", "two", "src")
+
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	restarted, err := NewDiskCache[string](time.Hour, dir, StringCodec, WithMaxEntries(1))
+	require.NoError(t, err)
+	_, _, found := restarted.GetStale("v1")
+	assert.False(t, found)
+	val, _, found := restarted.Get("v2")
+	require.True(t, found)
+	assert.Equal(t, "two", val)
+}
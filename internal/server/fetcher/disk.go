@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Codec converts cache values to and from bytes so they can be persisted.
+type Codec[T any] struct {
+	Marshal   func(T) ([]byte, error)
+	Unmarshal func([]byte) (T, error)
+}
+
+// BytesCodec persists []byte values unchanged.
+var BytesCodec = Codec[[]byte]{
+	Marshal:   func(b []byte) ([]byte, error) { return b, nil },
+	Unmarshal: func(b []byte) ([]byte, error) { return b, nil },
+}
+
+// StringCodec persists string values as their UTF-8 bytes.
+var StringCodec = Codec[string]{
+	Marshal:   func(s string) ([]byte, error) { return []byte(s), nil },
+	Unmarshal: func(b []byte) (string, error) { return string(b), nil },
+}
+
+// JSONCodec returns a codec that persists values as JSON.
+func JSONCodec[T any]() Codec[T] {
+	return Codec[T]{
+		Marshal: func(v T) ([]byte, error) { return json.Marshal(v) },
+		Unmarshal: func(b []byte) (T, error) {
+			var v T
+			err := json.Unmarshal(b, &v)
+			return v, err
+		},
+	}
+}
+
+// diskRecord is the on-disk representation of a cache entry.
+type diskRecord struct {
+	Key       string    `json:"key"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Value     []byte    `json:"value"`
+}
+
+// diskStore persists cache entries as one JSON file per key.
+type diskStore[T any] struct {
+	dir   string
+	codec Codec[T]
+}
+
+func newDiskStore[T any](dir string, codec Codec[T]) (*diskStore[T], error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &diskStore[T]{dir: dir, codec: codec}, nil
+}
+
+func (d *diskStore[T]) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load reads the entry for key. Unreadable or corrupt entries are treated
+// as misses so that a damaged cache never blocks a fresh fetch.
+func (d *diskStore[T]) load(key string) (cacheItem[T], bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("failed to read disk cache entry", "key", key, "error", err)
+		}
+		return cacheItem[T]{}, false
+	}
+
+	var rec diskRecord
+	if err := json.Unmarshal(data, &rec); err != nil || rec.Key != key {
+		slog.Warn("ignoring corrupt disk cache entry", "key", key)
+		return cacheItem[T]{}, false
+	}
+	value, err := d.codec.Unmarshal(rec.Value)
+	if err != nil {
+		slog.Warn("failed to decode disk cache entry", "key", key, "error", err)
+		return cacheItem[T]{}, false
+	}
+	return cacheItem[T]{key: key, value: value, source: rec.Source, cacheTime: rec.FetchedAt}, true
+}
+
+// store writes the entry for key atomically. Failures are logged rather than
+// returned because the in-memory cache still holds the value.
+func (d *diskStore[T]) store(key string, item cacheItem[T]) {
+	if err := d.write(key, item); err != nil {
+		slog.Warn("failed to write disk cache entry", "key", key, "error", err)
+	}
+}
+
+func (d *diskStore[T]) write(key string, item cacheItem[T]) error {
+	value, err := d.codec.Marshal(item.value)
+	if err != nil {
+		return fmt.Errorf("encoding value: %w", err)
+	}
+	data, err := json.Marshal(diskRecord{
+		Key:       key,
+		Source:    item.source,
+		FetchedAt: item.cacheTime,
+		Value:     value,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+// remove deletes the entry for key, if present.
+func (d *diskStore[T]) remove(key string) {
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("failed to remove disk cache entry", "key", key, "error", err)
+	}
+}
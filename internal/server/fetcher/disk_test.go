@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCacheSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := NewDiskCache[[]byte](time.Hour, dir, BytesCodec)
+	require.NoError(t, err)
+	first.Set("lexicon", []byte("terms"), "https://example.com/lexicon.yaml")
+
+	restarted, err := NewDiskCache[[]byte](time.Hour, dir, BytesCodec)
+	require.NoError(t, err)
+	val, source, found := restarted.Get("lexicon")
+	require.True(t, found)
+	assert.Equal(t, []byte("terms"), val)
+	assert.Equal(t, "https://example.com/lexicon.yaml", source)
+
+	_, _, found = restarted.Get("missing")
+	assert.False(t, found)
+}
+
+func TestDiskCacheHonoursTTLAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := NewDiskCache[string](time.Hour, dir, StringCodec)
+	require.NoError(t, err)
+	first.Set("version", "v1.0.0", "registry")
+
+	restarted, err := NewDiskCache[string](time.Nanosecond, dir, StringCodec)
+	require.NoError(t, err)
+	_, _, found := restarted.Get("version")
+	assert.False(t, found, "entry fetched before the TTL window must be expired")
+
+	val, _, found := restarted.GetStale("version")
+	require.True(t, found, "expired entries remain available as stale")
+	assert.Equal(t, "v1.0.0", val)
+}
+
+func TestDiskCacheJSONCodec(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := NewDiskCache[[]string](time.Hour, dir, JSONCodec[[]string]())
+	require.NoError(t, err)
+	first.Set("versions", []string{"v1.0.0", "v1.1.0"}, "registry")
+	first.Set("none", []string{}, "registry")
+
+	restarted, err := NewDiskCache[[]string](time.Hour, dir, JSONCodec[[]string]())
+	require.NoError(t, err)
+	val, source, found := restarted.Get("versions")
+	require.True(t, found)
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, val)
+	assert.Equal(t, "registry", source)
+
+	val, _, found = restarted.Get("none")
+	require.True(t, found)
+	assert.Empty(t, val)
+}
+
+func TestDiskCacheIgnoresCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache[[]byte](time.Hour, dir, BytesCodec)
+	require.NoError(t, err)
+	c.Set("key", []byte("value"), "source")
+
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, os.WriteFile(entries[0], []byte("{not json"), 0o600))
+
+	restarted, err := NewDiskCache[[]byte](time.Hour, dir, BytesCodec)
+	require.NoError(t, err)
+	_, _, found := restarted.GetStale("key")
+	assert.False(t, found)
+}
+
+func TestCachedFetcherServesStaleOnError(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewDiskCache[[]byte](time.Hour, dir, BytesCodec)
+	require.NoError(t, err)
+	first.Set("lexicon", []byte("stale terms"), "https://example.com/lexicon.yaml")
+
+	restarted, err := NewDiskCache[[]byte](time.Nanosecond, dir, BytesCodec)
+	require.NoError(t, err)
+	mock := &mockFetcher{err: errors.New("network unreachable")}
+	cf := NewCachedFetcher[[]byte](mock, restarted, "lexicon")
+
+	data, source, err := cf.Fetch(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("stale terms"), data)
+	assert.Equal(t, "https://example.com/lexicon.yaml", source)
+	assert.Equal(t, 1, mock.callCount, "a refetch is attempted before serving stale")
+}
+
+func TestDiskCacheLoadedEntriesKeepTheirKey(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewDiskCache[string](time.Hour, dir, StringCodec)
+	require.NoError(t, err)
+	first.Set("a", "value-a", "source-a")
+	first.Set("b", "value-b", "source-b")
+
+	restarted, err := NewDiskCache[string](time.Hour, dir, StringCodec, WithMaxEntries(1))
+	require.NoError(t, err)
+	val, _, found := restarted.Get("a")
+	require.True(t, found)
+	assert.Equal(t, "value-a", val)
+
+	// The second lookup is served from memory under its own key.
+	restarted.mu.Lock()
+	_, inMemory := restarted.items["a"]
+	keys := len(restarted.items)
+	restarted.mu.Unlock()
+	assert.True(t, inMemory)
+	assert.Equal(t, 1, keys)
+	require.NoError(t, os.Remove(restarted.disk.path("a")))
+	val, _, found = restarted.Get("a")
+	require.True(t, found, "entry must be reused from memory, not reloaded from disk")
+	assert.Equal(t, "value-a", val)
+
+	// Loading b evicts a, which removes a's file and leaves b's in place.
+	first.Set("a", "value-a", "source-a")
+	_, _, found = restarted.Get("b")
+	require.True(t, found)
+	_, err = os.Stat(restarted.disk.path("a"))
+	assert.ErrorIs(t, err, os.ErrNotExist, "evicting a removes its own file")
+	_, err = os.Stat(restarted.disk.path("b"))
+	assert.NoError(t, err)
+}
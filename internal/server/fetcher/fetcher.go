@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 )
 
@@ -83,11 +84,16 @@ func limitReader(r io.Reader, n int64) io.Reader {
 	return io.LimitReader(r, n)
 }
 
-// CachedFetcher wraps a Fetcher with caching behavior.
+// CachedFetcher wraps a Fetcher with caching behavior. Concurrent fetches
+// for the same cache key are coalesced so that only one reaches the source.
 type CachedFetcher[T any] struct {
 	fetcher Fetcher[T]
 	cache   *Cache[T]
 	key     string
+
+	// StaleWhileRevalidate returns an expired cache entry immediately and
+	// refreshes it in the background instead of blocking the caller.
+	StaleWhileRevalidate bool
 }
 
 // NewCachedFetcher creates a new cached fetcher that wraps the provided fetcher.
@@ -100,20 +106,76 @@ func NewCachedFetcher[T any](f Fetcher[T], cache *Cache[T], key string) *CachedF
 }
 
 // Fetch retrieves data, checking cache first and storing results in cache.
-// If refresh is true, bypasses cache and fetches fresh data.
+// If refresh is true, bypasses cache and fetches fresh data. If the fetch
+// fails, an expired cache entry is served as stale rather than failing.
 func (c *CachedFetcher[T]) Fetch(ctx context.Context, refresh bool) (T, string, error) {
 	if !refresh {
 		if val, source, found := c.cache.Get(c.key); found {
 			return val, source, nil
 		}
+		if c.StaleWhileRevalidate {
+			if val, source, found := c.cache.GetStale(c.key); found {
+				c.revalidate()
+				return val, source, nil
+			}
+		}
 	}
 
-	val, source, err := c.fetcher.Fetch(ctx)
+	val, source, err := c.fetchShared(ctx)
 	if err != nil {
+		if stale, staleSource, found := c.cache.GetStale(c.key); found {
+			slog.Warn("fetch failed, serving stale cache entry", "key", c.key, "error", err)
+			return stale, staleSource, nil
+		}
 		var zero T
 		return zero, "", err
 	}
-
-	c.cache.Set(c.key, val, source)
 	return val, source, nil
 }
+
+// fetchResult carries a fetched value through the coalescing group.
+type fetchResult[T any] struct {
+	value  T
+	source string
+}
+
+// fetchShared joins the in-flight fetch for the key, or starts one. The fetch
+// itself is not cancelled when one caller gives up, so other waiters and the
+// cache still receive its result.
+func (c *CachedFetcher[T]) fetchShared(ctx context.Context) (T, string, error) {
+	ch := c.cache.inflight.DoChan(c.key, c.fetchAndStore(context.WithoutCancel(ctx)))
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, "", ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			var zero T
+			return zero, "", res.Err
+		}
+		r := res.Val.(fetchResult[T])
+		return r.value, r.source, nil
+	}
+}
+
+// revalidate refreshes the cache entry in the background, unless a fetch for
+// the key is already in flight.
+func (c *CachedFetcher[T]) revalidate() {
+	ch := c.cache.inflight.DoChan(c.key, c.fetchAndStore(context.Background()))
+	go func() {
+		if res := <-ch; res.Err != nil {
+			slog.Warn("background cache refresh failed", "key", c.key, "error", res.Err)
+		}
+	}()
+}
+
+func (c *CachedFetcher[T]) fetchAndStore(ctx context.Context) func() (any, error) {
+	return func() (any, error) {
+		val, source, err := c.fetcher.Fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.Set(c.key, val, source)
+		return fetchResult[T]{value: val, source: source}, nil
+	}
+}
@@ -5,6 +5,8 @@ package fetcher
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -150,3 +152,102 @@ func TestCachedFetcher(t *testing.T) {
 		})
 	}
 }
+
+// gatedFetcher blocks every fetch until release is closed and counts calls.
+type gatedFetcher struct {
+	value   string
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func newGatedFetcher(value string) *gatedFetcher {
+	return &gatedFetcher{
+		value:   value,
+		started: make(chan struct{}, 16),
+		release: make(chan struct{}),
+	}
+}
+
+func (g *gatedFetcher) Fetch(_ context.Context) (string, string, error) {
+	g.calls.Add(1)
+	g.started <- struct{}{}
+	<-g.release
+	return g.value, "gated://source", nil
+}
+
+func TestCachedFetcherCoalescesConcurrentFetches(t *testing.T) {
+	cache := NewCache[string](time.Hour)
+	gated := newGatedFetcher("value")
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, errs[i] = NewCachedFetcher[string](gated, cache, "key").Fetch(context.Background(), false)
+		}()
+	}
+
+	<-gated.started
+	// Give the remaining callers time to join the in-flight fetch.
+	time.Sleep(50 * time.Millisecond)
+	close(gated.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), gated.calls.Load(), "only one fetch should reach the source")
+	for i := range callers {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "value", results[i])
+	}
+}
+
+func TestCachedFetcherCancelledCallerDoesNotAbortSharedFetch(t *testing.T) {
+	cache := NewCache[string](time.Hour)
+	gated := newGatedFetcher("value")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := NewCachedFetcher[string](gated, cache, "key").Fetch(ctx, false)
+		errCh <- err
+	}()
+
+	<-gated.started
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+
+	close(gated.release)
+	assert.Eventually(t, func() bool {
+		_, _, found := cache.Get("key")
+		return found
+	}, time.Second, 5*time.Millisecond, "the shared fetch still populates the cache")
+}
+
+func TestCachedFetcherStaleWhileRevalidate(t *testing.T) {
+	cache := NewCache[string](time.Nanosecond)
+	cache.Set("key", "stale", "gated://source")
+	gated := newGatedFetcher("fresh")
+
+	cf := NewCachedFetcher[string](gated, cache, "key")
+	cf.StaleWhileRevalidate = true
+
+	// The fetch is gated, so these calls only return because the stale
+	// value is served without waiting on the refresh.
+	for range 3 {
+		val, _, err := cf.Fetch(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, "stale", val)
+	}
+
+	<-gated.started
+	close(gated.release)
+	assert.Eventually(t, func() bool {
+		val, _, _ := cache.GetStale("key")
+		return val == "fresh"
+	}, time.Second, 5*time.Millisecond, "the background refresh updates the cache")
+	assert.Equal(t, int32(1), gated.calls.Load(), "concurrent revalidations are coalesced")
+}
@@ -7,12 +7,17 @@ import (
 	_ "embed"
 	"fmt"
 	"log/slog"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/load"
+	"cuelang.org/go/mod/modconfig"
+	"cuelang.org/go/mod/modfile"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
 	"github.com/gemaraproj/go-gemara"
 	"github.com/goccy/go-yaml"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -27,15 +32,40 @@ var capabilityMigrationCUE string
 //go:embed migrations/control_catalog.cue
 var controlMigrationCUE string
 
+//go:embed migrations/guidance_catalog.cue
+var guidanceMigrationCUE string
+
+//go:embed migrations/policy.cue
+var policyMigrationCUE string
+
+//go:embed migrations/evaluation_log.cue
+var evaluationLogMigrationCUE string
+
 //go:embed migrations/cue.mod/module.cue
 var moduleCUE string
 
 const migrateOverlayDir = "/cue/migrate"
 
+// MigrationSchemaVersions returns the Gemara module versions the built-in
+// migrations import, which a schema bundle must hold to migrate offline.
+func MigrationSchemaVersions() ([]string, error) {
+	f, err := modfile.Parse([]byte(moduleCUE), "module.cue")
+	if err != nil {
+		return nil, fmt.Errorf("parsing migration module file: %w", err)
+	}
+	var versions []string
+	for _, mv := range f.DepVersions() {
+		if mv.BasePath() == GemaraModulePath {
+			versions = append(versions, mv.Version())
+		}
+	}
+	return versions, nil
+}
+
 // MetadataMigrateGemaraArtifact describes the MigrateGemaraArtifact tool.
 var MetadataMigrateGemaraArtifact = &mcp.Tool{
 	Name:        "migrate_gemara_artifact",
-	Description: "Migrate a Gemara artifact to v1 schema using CUE transformations. When the artifact is missing metadata fields (common in older v0 artifacts), use artifact_type and gemara_version to supply them.",
+	Description: "Migrate a Gemara artifact between schema versions using CUE transformations, applying each registered migration step on the path from the artifact's gemara-version to target_version and reporting the changes of every step along with a field-level and unified diff of each migrated artifact (artifacts extracted from the input, such as the CapabilityCatalog of a v0 ThreatCatalog, are marked extracted and diffed as new files); set dry_run to omit the migrated content. Configured rule packs run after each built-in transformation and are listed in rule_packs. Each migrated artifact is validated against the target schema. Fields the migration cannot fill in are reported as todos; supply their values with overrides, and set strict to fail while any remain. When the artifact is missing metadata fields (common in older v0 artifacts), use artifact_type and gemara_version to supply them.",
 	InputSchema: map[string]interface{}{
 		"type":     "object",
 		"required": []string{"artifact_content"},
@@ -46,40 +76,106 @@ var MetadataMigrateGemaraArtifact = &mcp.Tool{
 			},
 			"artifact_type": map[string]interface{}{
 				"type":        "string",
-				"description": "Artifact type when metadata.type is missing. Infer from structure: threats → ThreatCatalog, controls → ControlCatalog.",
-				"enum":        []string{gemara.ThreatCatalogArtifact.String(), gemara.ControlCatalogArtifact.String()},
+				"description": "Artifact type when metadata.type is missing. Infer from structure: threats → ThreatCatalog, controls → ControlCatalog, guidelines → GuidanceCatalog, adherence → Policy, evaluations or evaluation-set → EvaluationLog.",
+				"enum": []string{
+					gemara.ThreatCatalogArtifact.String(),
+					gemara.ControlCatalogArtifact.String(),
+					gemara.GuidanceCatalogArtifact.String(),
+					gemara.PolicyArtifact.String(),
+					gemara.EvaluationLogArtifact.String(),
+				},
 			},
 			"gemara_version": map[string]interface{}{
 				"type":        "string",
 				"description": "Source gemara-version when metadata.gemara-version is missing (e.g. \"0.20.0\").",
 			},
+			"target_version": map[string]interface{}{
+				"type":        "string",
+				"description": "gemara-version to migrate to (default: the latest supported version, \"" + DefaultGemaraVersion + "\").",
+			},
+			"dry_run": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Return only the changes and diffs of each migrated artifact, without its full content.",
+			},
+			"overrides": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"description":          "Values for fields the migration cannot fill in, keyed by the override key of each reported TODO (\"<artifact type>:<YAML path>\") or by YAML path for every migrated artifact.",
+			},
+			"strict": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Fail the migration while any \"" + placeholderValue + "\" placeholder remains without a value in overrides.",
+			},
 		},
 	},
 }
 
 // InputMigrateGemaraArtifact is the input for the MigrateGemaraArtifact tool.
 type InputMigrateGemaraArtifact struct {
-	ArtifactContent string `json:"artifact_content"`
-	ArtifactType    string `json:"artifact_type"`
-	GemaraVersion   string `json:"gemara_version"`
+	ArtifactContent string            `json:"artifact_content"`
+	ArtifactType    string            `json:"artifact_type"`
+	GemaraVersion   string            `json:"gemara_version"`
+	TargetVersion   string            `json:"target_version,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+	Overrides       map[string]string `json:"overrides,omitempty"`
+	Strict          bool              `json:"strict,omitempty"`
 }
 
 // MigratedArtifact represents a single output artifact from the migration.
 type MigratedArtifact struct {
 	Type              string `json:"type"`
 	SuggestedFilename string `json:"suggested_filename"`
-	Content           string `json:"content"`
+	// Content is omitted in dry-run mode.
+	Content string `json:"content,omitempty"`
+	// Extracted reports an artifact split out of the input, such as the
+	// CapabilityCatalog of a v0 ThreatCatalog, rather than migrated from it.
+	Extracted bool `json:"extracted,omitempty"`
+	// Diff lists the field-level changes from the input artifact. It is
+	// empty for extracted artifacts.
+	Diff []FieldChange `json:"diff,omitempty"`
+	// UnifiedDiff is a unified diff of the input YAML against Content, or
+	// of an empty file for extracted artifacts.
+	UnifiedDiff string `json:"unified_diff,omitempty"`
+	// Validation is the result of validating Content against the target schema.
+	Validation *MigrationValidation `json:"validation,omitempty"`
+}
+
+// MigrationValidation is the result of validating a migrated artifact
+// against its definition in the target schema.
+type MigrationValidation struct {
+	Definition  string              `json:"definition"`
+	Version     string              `json:"version"`
+	Valid       bool                `json:"valid"`
+	Errors      []string            `json:"errors,omitempty"`
+	Diagnostics []schema.Diagnostic `json:"diagnostics,omitempty"`
+	Message     string              `json:"message"`
+}
+
+// MigrationHop records the changes made by one step of the migration path.
+type MigrationHop struct {
+	Type    string   `json:"type"`
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Changes []string `json:"changes"`
 }
 
 // OutputMigrateGemaraArtifact is the output for the MigrateGemaraArtifact tool.
 type OutputMigrateGemaraArtifact struct {
 	Artifacts []MigratedArtifact `json:"artifacts,omitempty"`
-	Changes   []string           `json:"changes"`
-	Message   string             `json:"message"`
+	// Changes lists the changes of every hop, in the order they were applied.
+	Changes []string       `json:"changes"`
+	Hops    []MigrationHop `json:"hops,omitempty"`
+	// RulePacks lists the configured rule packs applied to the migrated
+	// artifacts, in the order they were first applied.
+	RulePacks []string `json:"rule_packs,omitempty"`
+	// TODOs lists the placeholders left in the migrated artifacts.
+	TODOs   []MigrationTODO `json:"todos,omitempty"`
+	Message string          `json:"message"`
 }
 
 // MigrateGemaraArtifact migrates a Gemara artifact to v1 schema using the pattern - YAML → CUE transformation → YAML.
-func MigrateGemaraArtifact(_ context.Context, _ *mcp.CallToolRequest, input InputMigrateGemaraArtifact) (*mcp.CallToolResult, OutputMigrateGemaraArtifact, error) {
+// The rule packs run after each built-in transformation.
+func MigrateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputMigrateGemaraArtifact, schemas SchemaSource, packs []RulePack) (*mcp.CallToolResult, OutputMigrateGemaraArtifact, error) {
 	if input.ArtifactContent == "" {
 		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("artifact_content is required")
 	}
@@ -104,27 +200,141 @@ func MigrateGemaraArtifact(_ context.Context, _ *mcp.CallToolRequest, input Inpu
 		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("invalid artifact metadata: %w", err)
 	}
 
-	if meta.GemaraVersion == DefaultGemaraVersion {
+	target := DefaultGemaraVersion
+	if input.TargetVersion != "" {
+		target = input.TargetVersion
+	}
+	targetVersion, ok := moduleVersion(target)
+	if !ok {
+		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("invalid target_version %q", target)
+	}
+	sourceVersion, ok := moduleVersion(meta.GemaraVersion)
+	if !ok {
+		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("invalid metadata.gemara-version %q", meta.GemaraVersion)
+	}
+	if sourceVersion == targetVersion {
 		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf(
-			"artifact is already at target gemara-version %q", DefaultGemaraVersion)
+			"artifact is already at target gemara-version %q", target)
 	}
 
 	slog.Info("migrating artifact",
 		"type", meta.Type,
 		"source_version", meta.GemaraVersion,
+		"target_version", targetVersion,
 	)
 
-	cueCtx := cuecontext.New()
+	output, err := migrateChain(&cueMigrator{ctx: cuecontext.New(), registry: schemas.Registry, packs: packs}, migrationRegistry, meta.Type, root, meta.GemaraVersion, targetVersion)
+	if err != nil {
+		return nil, OutputMigrateGemaraArtifact{}, err
+	}
+
+	used := make(map[string]bool)
+	for i := range output.Artifacts {
+		a := &output.Artifacts[i]
+		todos, err := resolvePlaceholders(a, input.Overrides, used)
+		if err != nil {
+			return nil, OutputMigrateGemaraArtifact{}, err
+		}
+		if a.Type == meta.Type.String() {
+			a.Content = preserveFormatting(input.ArtifactContent, a.Content)
+		}
+		locatePlaceholders(a.Content, todos)
+		output.TODOs = append(output.TODOs, todos...)
+	}
+	for _, key := range slices.Sorted(maps.Keys(input.Overrides)) {
+		if !used[key] {
+			return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("override %q does not match any placeholder in the migrated artifacts", key)
+		}
+	}
+	if input.Strict && len(output.TODOs) > 0 {
+		keys := make([]string, 0, len(output.TODOs))
+		for _, todo := range output.TODOs {
+			keys = append(keys, todo.Override)
+		}
+		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("strict: %d placeholder(s) need values in overrides: %s",
+			len(keys), strings.Join(keys, ", "))
+	}
 
-	switch meta.Type {
-	case gemara.ThreatCatalogArtifact:
-		return migrateThreatCatalog(cueCtx, root, meta.GemaraVersion)
-	case gemara.ControlCatalogArtifact:
-		return migrateSimpleArtifact(cueCtx, root, controlMigrationCUE, meta.Type, "controls.yaml", meta.GemaraVersion)
-	default:
-		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf(
-			"unsupported artifact type %q for migration", meta.Type)
+	failed, validatedWith, validateErr := validateMigrated(ctx, output.Artifacts, schemas, targetVersion)
+
+	for i := range output.Artifacts {
+		a := &output.Artifacts[i]
+		diff := diffExtracted
+		if a.Type == meta.Type.String() {
+			diff = func(a *MigratedArtifact) error { return diffArtifact(input.ArtifactContent, a) }
+		}
+		if err := diff(a); err != nil {
+			return nil, OutputMigrateGemaraArtifact{}, err
+		}
+		if input.DryRun {
+			output.Artifacts[i].Content = ""
+		}
+	}
+
+	output.Message = fmt.Sprintf("Migrated %s from %s → %s: %d artifact(s) produced",
+		meta.Type, meta.GemaraVersion, targetVersion, len(output.Artifacts))
+	if len(output.RulePacks) > 0 {
+		output.Message += fmt.Sprintf("; rule packs applied: %s", strings.Join(output.RulePacks, ", "))
+	}
+	if len(output.TODOs) > 0 {
+		output.Message += fmt.Sprintf("; %d placeholder(s) need values", len(output.TODOs))
+	}
+	switch {
+	case validateErr != nil:
+		output.Message += fmt.Sprintf("; artifacts not validated: %v", validateErr)
+	case failed > 0:
+		output.Message += fmt.Sprintf("; %d artifact(s) failed validation against schema %s", failed, validatedWith)
 	}
+	if input.DryRun {
+		output.Message = "Dry run: " + output.Message
+	}
+	slog.Info("migration complete",
+		"type", meta.Type,
+		"hops", len(output.Hops),
+		"artifacts", len(output.Artifacts),
+		"changes", len(output.Changes),
+		"rule_packs", len(output.RulePacks),
+		"invalid", failed,
+	)
+	return nil, output, nil
+}
+
+// validateMigrated validates each artifact against its definition in the
+// schema published for the target gemara-version, or the latest schema when
+// no module was published for it. It records the result on each artifact and
+// returns the number that failed and the schema version used.
+func validateMigrated(ctx context.Context, artifacts []MigratedArtifact, schemas SchemaSource, target string) (int, string, error) {
+	version, ok := schemas.publishedVersion(ctx, target)
+	if !ok {
+		version = defaultSchemaVersion
+	}
+	cueVal, _, err := schemas.Schema(version).Fetch(ctx, false)
+	if err != nil {
+		slog.Warn("migrated artifacts not validated", "error", err)
+		return 0, version, fmt.Errorf("loading schema: %w", err)
+	}
+
+	failed := 0
+	for i := range artifacts {
+		a := &artifacts[i]
+		v := &MigrationValidation{Definition: normalizeDefinition(a.Type), Version: version}
+		result, err := schema.Validate(cueVal, v.Definition, a.Content)
+		if err != nil {
+			v.Errors = []string{err.Error()}
+			v.Message = fmt.Sprintf("Validation failed: %v", err)
+		} else {
+			v.Valid = result.Valid
+			v.Errors = result.Errors
+			v.Diagnostics = result.Diagnostics
+			v.Message = result.Message
+		}
+		if !v.Valid {
+			failed++
+			slog.Warn("migrated artifact failed validation", "type", a.Type, "version", version, "errors", len(v.Errors))
+		}
+		a.Validation = v
+	}
+	return failed, version, nil
 }
 
 // enrichMetadata fills missing metadata fields from input parameters.
@@ -157,7 +367,9 @@ func enrichMetadata(root map[string]interface{}, input InputMigrateGemaraArtifac
 	return metaRaw, nil
 }
 
-func migrateThreatCatalog(cueCtx *cue.Context, root map[string]interface{}, sourceVersion string) (*mcp.CallToolResult, OutputMigrateGemaraArtifact, error) {
+// migrateThreatCatalog migrates a ThreatCatalog to v1, extracting inline
+// capabilities into a standalone CapabilityCatalog.
+func migrateThreatCatalog(m *cueMigrator, root map[string]interface{}, sourceVersion, targetVersion string) ([]MigratedArtifact, []string, error) {
 	tcType := gemara.ThreatCatalogArtifact
 
 	title, _ := root["title"].(string)
@@ -167,12 +379,12 @@ func migrateThreatCatalog(cueCtx *cue.Context, root map[string]interface{}, sour
 	}
 
 	tcExtras := map[string]interface{}{
-		"target_gemara_version":    DefaultGemaraVersion,
+		"target_gemara_version":    targetVersion,
 		"capability_catalog_title": capTitle,
 	}
-	tcYAML, err := cueMigrate(cueCtx, threatMigrationCUE, root, tcExtras)
+	tcYAML, err := m.migrate(threatMigrationCUE, root, tcExtras)
 	if err != nil {
-		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("migrating %s: %w", tcType, err)
+		return nil, nil, fmt.Errorf("migrating %s: %w", tcType, err)
 	}
 
 	artifacts := []MigratedArtifact{{
@@ -181,18 +393,18 @@ func migrateThreatCatalog(cueCtx *cue.Context, root map[string]interface{}, sour
 		Content:           tcYAML,
 	}}
 	changes := []string{
-		fmt.Sprintf("Updated metadata.gemara-version from %q to %q", sourceVersion, DefaultGemaraVersion),
+		fmt.Sprintf("Updated metadata.gemara-version from %q to %q", sourceVersion, targetVersion),
 	}
 
 	if capsRaw, ok := root["capabilities"]; ok {
 		if caps, ok := capsRaw.([]interface{}); ok && len(caps) > 0 {
 			capExtras := map[string]interface{}{
-				"target_gemara_version": DefaultGemaraVersion,
+				"target_gemara_version": targetVersion,
 				"capability_title":      capTitle,
 			}
-			capYAML, err := cueMigrate(cueCtx, capabilityMigrationCUE, root, capExtras)
+			capYAML, err := m.migrate(capabilityMigrationCUE, root, capExtras)
 			if err != nil {
-				return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("extracting %s: %w", gemara.CapabilityCatalogArtifact, err)
+				return nil, nil, fmt.Errorf("extracting %s: %w", gemara.CapabilityCatalogArtifact, err)
 			}
 
 			capType := gemara.CapabilityCatalogArtifact
@@ -209,56 +421,79 @@ func migrateThreatCatalog(cueCtx *cue.Context, root map[string]interface{}, sour
 		}
 	}
 
-	msg := fmt.Sprintf("Migrated %s from %s → %s: %d artifact(s) produced",
-		tcType, sourceVersion, DefaultGemaraVersion, len(artifacts))
-	slog.Info("migration complete",
-		"type", tcType,
-		"artifacts", len(artifacts),
-		"changes", len(changes),
-	)
-
-	return nil, OutputMigrateGemaraArtifact{
-		Artifacts: artifacts,
-		Changes:   changes,
-		Message:   msg,
-	}, nil
+	return artifacts, changes, nil
 }
 
-func migrateSimpleArtifact(cueCtx *cue.Context, root map[string]interface{}, cueSrc string, artifactType gemara.ArtifactType, filename, sourceVersion string) (*mcp.CallToolResult, OutputMigrateGemaraArtifact, error) {
-	extras := map[string]interface{}{
-		"target_gemara_version": DefaultGemaraVersion,
+// simpleMigration returns a migration function that applies cueSrc to
+// produce a single artifact of the same type.
+func simpleMigration(cueSrc string, artifactType gemara.ArtifactType, filename string) migrateFunc {
+	return func(m *cueMigrator, root map[string]interface{}, sourceVersion, targetVersion string) ([]MigratedArtifact, []string, error) {
+		extras := map[string]interface{}{
+			"target_gemara_version": targetVersion,
+		}
+		outputYAML, err := m.migrate(cueSrc, root, extras)
+		if err != nil {
+			return nil, nil, fmt.Errorf("migrating %s: %w", artifactType, err)
+		}
+
+		return []MigratedArtifact{{
+			Type:              artifactType.String(),
+			SuggestedFilename: filename,
+			Content:           outputYAML,
+		}}, []string{
+			fmt.Sprintf("Updated metadata.gemara-version from %q to %q", sourceVersion, targetVersion),
+		}, nil
 	}
-	outputYAML, err := cueMigrate(cueCtx, cueSrc, root, extras)
+}
+
+// cueMigrate loads a CUE migration via the module system (resolving imports),
+// fills the input path with YAML data, extracts the output path, applies each
+// rule pack to it, and encodes the result as YAML. It returns the names of the
+// packs that applied.
+func cueMigrate(cueCtx *cue.Context, reg modconfig.Registry, cueSrc string, inputData map[string]interface{}, extras map[string]interface{}, packs []RulePack) (string, []string, error) {
+	migration, err := loadMigration(cueCtx, reg, cueSrc, inputData, extras)
 	if err != nil {
-		return nil, OutputMigrateGemaraArtifact{}, fmt.Errorf("migrating %s: %w", artifactType, err)
+		return "", nil, err
 	}
 
-	changes := []string{
-		fmt.Sprintf("Updated metadata.gemara-version from %q to %q", sourceVersion, DefaultGemaraVersion),
+	outputVal := migration.LookupPath(cue.ParsePath("output"))
+	if err := outputVal.Err(); err != nil {
+		return "", nil, fmt.Errorf("evaluating migration output: %w", err)
 	}
-	msg := fmt.Sprintf("Migrated %s from %s → %s: 1 artifact(s) produced",
-		artifactType, sourceVersion, DefaultGemaraVersion)
-	slog.Info("migration complete",
-		"type", artifactType,
-		"artifacts", 1,
-		"changes", len(changes),
-	)
 
-	return nil, OutputMigrateGemaraArtifact{
-		Artifacts: []MigratedArtifact{{
-			Type:              artifactType.String(),
-			SuggestedFilename: filename,
-			Content:           outputYAML,
-		}},
-		Changes: changes,
-		Message: msg,
-	}, nil
+	var applied []string
+	for _, pack := range packs {
+		packExtras := maps.Clone(extras)
+		if packExtras == nil {
+			packExtras = make(map[string]interface{})
+		}
+		packExtras["output"] = outputVal
+		rules, err := loadMigration(cueCtx, reg, pack.Source, inputData, packExtras)
+		if err != nil {
+			return "", nil, fmt.Errorf("rule pack %q: %w", pack.Name, err)
+		}
+		result := rules.LookupPath(cue.ParsePath("result"))
+		if !result.Exists() {
+			continue
+		}
+		if err := result.Err(); err != nil {
+			return "", nil, fmt.Errorf("rule pack %q: evaluating result: %w", pack.Name, err)
+		}
+		outputVal = result
+		applied = append(applied, pack.Name)
+	}
+
+	content, err := cueValueToYAML(outputVal)
+	if err != nil {
+		return "", nil, err
+	}
+	return content, applied, nil
 }
 
-// cueMigrate loads a CUE migration via the module system (resolving imports),
-// fills the input path with YAML data, extracts the output path, and encodes
-// the result as YAML.
-func cueMigrate(cueCtx *cue.Context, cueSrc string, inputData map[string]interface{}, extras map[string]interface{}) (string, error) {
+// loadMigration builds cueSrc as package migrate in the migration module
+// overlay, resolving its dependencies from reg, and fills in the input path
+// and extras.
+func loadMigration(cueCtx *cue.Context, reg modconfig.Registry, cueSrc string, inputData map[string]interface{}, extras map[string]interface{}) (cue.Value, error) {
 	// FIXME(jpower432): Is this the correct way to load a local module?
 	overlay := map[string]load.Source{
 		filepath.Join(migrateOverlayDir, "migrate.cue"):           load.FromString(cueSrc),
@@ -266,33 +501,29 @@ func cueMigrate(cueCtx *cue.Context, cueSrc string, inputData map[string]interfa
 	}
 
 	instances := load.Instances([]string{"."}, &load.Config{
-		Dir:     migrateOverlayDir,
-		Overlay: overlay,
-		Package: "migrate",
+		Dir:      migrateOverlayDir,
+		Overlay:  overlay,
+		Package:  "migrate",
+		Registry: reg,
 	})
 	if len(instances) == 0 {
-		return "", fmt.Errorf("loading migration CUE: no instances returned")
+		return cue.Value{}, fmt.Errorf("loading migration CUE: no instances returned")
 	}
 	if err := instances[0].Err; err != nil {
-		return "", fmt.Errorf("loading migration CUE: %w", err)
+		return cue.Value{}, fmt.Errorf("loading migration CUE: %w", err)
 	}
 
+	// Errors are checked once the inputs are filled in, since guards such as
+	// "if output.metadata.type == ..." cannot be evaluated without them.
 	migration := cueCtx.BuildInstance(instances[0])
-	if err := migration.Err(); err != nil {
-		return "", fmt.Errorf("building migration: %w", err)
-	}
-
 	unified := migration.FillPath(cue.ParsePath("input"), inputData)
 	for k, v := range extras {
 		unified = unified.FillPath(cue.ParsePath(k), v)
 	}
-
-	outputVal := unified.LookupPath(cue.ParsePath("output"))
-	if err := outputVal.Err(); err != nil {
-		return "", fmt.Errorf("evaluating migration output: %w", err)
+	if err := unified.Err(); err != nil {
+		return cue.Value{}, fmt.Errorf("building migration: %w", err)
 	}
-
-	return cueValueToYAML(outputVal)
+	return unified, nil
 }
 
 // cueValueToYAML walks a CUE value tree and marshals it to YAML,
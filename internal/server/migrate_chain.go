@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gemaraproj/go-gemara"
+	"golang.org/x/mod/semver"
+)
+
+// gemaraV1 is the schema version produced by the v0 migrations; it is the
+// version of the gemara module the migration CUE depends on.
+const gemaraV1 = "v1.0.0"
+
+// migrateFunc migrates the artifact in root to targetVersion, returning the
+// artifacts it produces and a description of each change. sourceVersion is
+// the artifact's gemara-version as written, for reporting.
+type migrateFunc func(m *cueMigrator, root map[string]interface{}, sourceVersion, targetVersion string) ([]MigratedArtifact, []string, error)
+
+// migrationStep migrates one artifact type from one schema version to the next.
+type migrationStep struct {
+	artifactType gemara.ArtifactType
+	// from is the semver prefix of the versions the step accepts: a major
+	// ("v0") or major.minor ("v1.0") version.
+	from string
+	// to is the canonical version the step produces.
+	to      string
+	migrate migrateFunc
+}
+
+// migrationRegistry lists every known migration step. A step may produce
+// artifacts of other types, which continue along their own path.
+var migrationRegistry = []migrationStep{
+	{artifactType: gemara.ThreatCatalogArtifact, from: "v0", to: gemaraV1, migrate: migrateThreatCatalog},
+	{artifactType: gemara.ControlCatalogArtifact, from: "v0", to: gemaraV1, migrate: simpleMigration(controlMigrationCUE, gemara.ControlCatalogArtifact, "controls.yaml")},
+	{artifactType: gemara.GuidanceCatalogArtifact, from: "v0", to: gemaraV1, migrate: simpleMigration(guidanceMigrationCUE, gemara.GuidanceCatalogArtifact, "guidance.yaml")},
+	{artifactType: gemara.PolicyArtifact, from: "v0", to: gemaraV1, migrate: simpleMigration(policyMigrationCUE, gemara.PolicyArtifact, "policy.yaml")},
+	{artifactType: gemara.EvaluationLogArtifact, from: "v0", to: gemaraV1, migrate: simpleMigration(evaluationLogMigrationCUE, gemara.EvaluationLogArtifact, "evaluation-log.yaml")},
+}
+
+// accepts reports whether the step applies to an artifact at version.
+func (s migrationStep) accepts(version string) bool {
+	return semver.Major(version) == s.from || semver.MajorMinor(version) == s.from
+}
+
+// findMigrationPath returns the shortest sequence of steps that migrates an
+// artifact of the given type from one canonical version to another. Steps
+// only move forward, so downgrades have no path.
+func findMigrationPath(steps []migrationStep, artifactType gemara.ArtifactType, from, to string) ([]migrationStep, error) {
+	var candidates []migrationStep
+	for _, s := range steps {
+		if s.artifactType == artifactType {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("unsupported artifact type %q for migration", artifactType)
+	}
+
+	type node struct {
+		version string
+		path    []migrationStep
+	}
+	queue := []node{{version: from}}
+	visited := map[string]bool{from: true}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.version == to {
+			return n.path, nil
+		}
+		for _, s := range candidates {
+			if !s.accepts(n.version) || semver.Compare(s.to, n.version) <= 0 || semver.Compare(s.to, to) > 0 || visited[s.to] {
+				continue
+			}
+			visited[s.to] = true
+			path := append(append([]migrationStep(nil), n.path...), s)
+			queue = append(queue, node{version: s.to, path: path})
+		}
+	}
+	return nil, fmt.Errorf("no migration path for %s from %s to %s", artifactType, from, to)
+}
+
+// pendingArtifact is an artifact still being carried along the migration path.
+type pendingArtifact struct {
+	artifactType gemara.ArtifactType
+	// version is the artifact's gemara-version as written.
+	version  string
+	root     map[string]interface{}
+	migrated MigratedArtifact
+}
+
+// migrateChain migrates the artifact in root, and every artifact split off
+// from it along the way, to targetVersion one step at a time. Rule packs m
+// applies are reported as changes of the hop that applied them.
+func migrateChain(m *cueMigrator, steps []migrationStep, artifactType gemara.ArtifactType, root map[string]interface{}, sourceVersion, targetVersion string) (OutputMigrateGemaraArtifact, error) {
+	var output OutputMigrateGemaraArtifact
+	queue := []pendingArtifact{{artifactType: artifactType, version: sourceVersion, root: root}}
+	for len(queue) > 0 {
+		doc := queue[0]
+		queue = queue[1:]
+
+		from, _ := moduleVersion(doc.version)
+		if from == targetVersion {
+			output.Artifacts = append(output.Artifacts, doc.migrated)
+			continue
+		}
+
+		path, err := findMigrationPath(steps, doc.artifactType, from, targetVersion)
+		if err != nil {
+			return OutputMigrateGemaraArtifact{}, err
+		}
+		step := path[0]
+
+		if doc.root == nil {
+			if doc.root, err = parseYAMLMap(doc.migrated.Content); err != nil {
+				return OutputMigrateGemaraArtifact{}, fmt.Errorf("reading %s migrated to %s: %w", doc.artifactType, doc.version, err)
+			}
+		}
+
+		seen := len(m.applied)
+		produced, changes, err := step.migrate(m, doc.root, doc.version, step.to)
+		if err != nil {
+			return OutputMigrateGemaraArtifact{}, err
+		}
+		for _, name := range m.applied[seen:] {
+			if change := fmt.Sprintf("Applied rule pack %q", name); !slices.Contains(changes, change) {
+				changes = append(changes, change)
+			}
+			if !slices.Contains(output.RulePacks, name) {
+				output.RulePacks = append(output.RulePacks, name)
+			}
+		}
+		output.Hops = append(output.Hops, MigrationHop{
+			Type:    doc.artifactType.String(),
+			From:    doc.version,
+			To:      step.to,
+			Changes: changes,
+		})
+		output.Changes = append(output.Changes, changes...)
+
+		for _, a := range produced {
+			var t gemara.ArtifactType
+			if err := t.UnmarshalYAML([]byte(a.Type)); err != nil {
+				return OutputMigrateGemaraArtifact{}, fmt.Errorf("migration produced unknown artifact type %q: %w", a.Type, err)
+			}
+			queue = append(queue, pendingArtifact{artifactType: t, version: step.to, migrated: a})
+		}
+	}
+	return output, nil
+}
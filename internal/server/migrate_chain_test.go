@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gemaraproj/go-gemara"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMigration bumps gemara-version and, when split is set, also produces a
+// CapabilityCatalog at the target version.
+func fakeMigration(artifactType gemara.ArtifactType, split bool) migrateFunc {
+	return func(_ *cueMigrator, root map[string]interface{}, sourceVersion, targetVersion string) ([]MigratedArtifact, []string, error) {
+		content := fmt.Sprintf("metadata:\n  type: %s\n  gemara-version: %s\n", artifactType, targetVersion)
+		produced := []MigratedArtifact{{Type: artifactType.String(), Content: content}}
+		if split {
+			produced = append(produced, MigratedArtifact{
+				Type:    gemara.CapabilityCatalogArtifact.String(),
+				Content: fmt.Sprintf("metadata:\n  type: CapabilityCatalog\n  gemara-version: %s\n", targetVersion),
+			})
+		}
+		return produced, []string{fmt.Sprintf("%s %s → %s", artifactType, sourceVersion, targetVersion)}, nil
+	}
+}
+
+func testMigrationSteps() []migrationStep {
+	return []migrationStep{
+		{artifactType: gemara.ThreatCatalogArtifact, from: "v0", to: "v1.0.0", migrate: fakeMigration(gemara.ThreatCatalogArtifact, true)},
+		{artifactType: gemara.ThreatCatalogArtifact, from: "v1.0", to: "v1.1.0", migrate: fakeMigration(gemara.ThreatCatalogArtifact, false)},
+		{artifactType: gemara.CapabilityCatalogArtifact, from: "v1.0", to: "v1.1.0", migrate: fakeMigration(gemara.CapabilityCatalogArtifact, false)},
+		{artifactType: gemara.ControlCatalogArtifact, from: "v0", to: "v1.0.0", migrate: fakeMigration(gemara.ControlCatalogArtifact, false)},
+	}
+}
+
+func TestFindMigrationPath(t *testing.T) {
+	steps := testMigrationSteps()
+	tests := []struct {
+		name         string
+		artifactType gemara.ArtifactType
+		from         string
+		to           string
+		wantTo       []string
+		errContains  string
+	}{
+		{
+			name:         "single hop",
+			artifactType: gemara.ThreatCatalogArtifact,
+			from:         "v0.20.0",
+			to:           "v1.0.0",
+			wantTo:       []string{"v1.0.0"},
+		},
+		{
+			name:         "multiple hops",
+			artifactType: gemara.ThreatCatalogArtifact,
+			from:         "v0.18.0",
+			to:           "v1.1.0",
+			wantTo:       []string{"v1.0.0", "v1.1.0"},
+		},
+		{
+			name:         "patch release accepted by minor step",
+			artifactType: gemara.ThreatCatalogArtifact,
+			from:         "v1.0.2",
+			to:           "v1.1.0",
+			wantTo:       []string{"v1.1.0"},
+		},
+		{
+			name:         "target beyond registry",
+			artifactType: gemara.ControlCatalogArtifact,
+			from:         "v0.20.0",
+			to:           "v1.1.0",
+			errContains:  "no migration path for ControlCatalog from v0.20.0 to v1.1.0",
+		},
+		{
+			name:         "downgrade",
+			artifactType: gemara.ThreatCatalogArtifact,
+			from:         "v1.1.0",
+			to:           "v1.0.0",
+			errContains:  "no migration path",
+		},
+		{
+			name:         "unsupported type",
+			artifactType: gemara.RiskCatalogArtifact,
+			from:         "v0.1.0",
+			to:           "v1.0.0",
+			errContains:  "unsupported artifact type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := findMigrationPath(steps, tt.artifactType, tt.from, tt.to)
+			if tt.errContains != "" {
+				require.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			var got []string
+			for _, s := range path {
+				got = append(got, s.to)
+			}
+			assert.Equal(t, tt.wantTo, got)
+		})
+	}
+}
+
+func TestMigrateChain(t *testing.T) {
+	root := map[string]interface{}{
+		"metadata": map[string]interface{}{"type": "ThreatCatalog", "gemara-version": "0.20.0"},
+	}
+	output, err := migrateChain(&cueMigrator{}, testMigrationSteps(), gemara.ThreatCatalogArtifact, root, "0.20.0", "v1.1.0")
+	require.NoError(t, err)
+
+	var hops []string
+	for _, h := range output.Hops {
+		hops = append(hops, fmt.Sprintf("%s %s → %s", h.Type, h.From, h.To))
+		assert.Len(t, h.Changes, 1)
+	}
+	assert.Equal(t, []string{
+		"ThreatCatalog 0.20.0 → v1.0.0",
+		"ThreatCatalog v1.0.0 → v1.1.0",
+		"CapabilityCatalog v1.0.0 → v1.1.0",
+	}, hops)
+	assert.Equal(t, hops, output.Changes, "changes are reported in hop order")
+
+	require.Len(t, output.Artifacts, 2)
+	assert.Equal(t, gemara.ThreatCatalogArtifact.String(), output.Artifacts[0].Type)
+	assert.Contains(t, output.Artifacts[0].Content, "gemara-version: v1.1.0")
+	assert.Equal(t, gemara.CapabilityCatalogArtifact.String(), output.Artifacts[1].Type)
+	assert.Contains(t, output.Artifacts[1].Content, "gemara-version: v1.1.0")
+}
+
+func TestMigrateChainNoPathForProducedArtifact(t *testing.T) {
+	steps := testMigrationSteps()[:2]
+	root := map[string]interface{}{"metadata": map[string]interface{}{"type": "ThreatCatalog"}}
+	_, err := migrateChain(&cueMigrator{}, steps, gemara.ThreatCatalogArtifact, root, "0.20.0", "v1.1.0")
+	require.ErrorContains(t, err, "unsupported artifact type \"CapabilityCatalog\"")
+}
@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// FieldChangeOp is the kind of change a migration made to a field.
+type FieldChangeOp string
+
+const (
+	FieldAdded   FieldChangeOp = "added"
+	FieldRemoved FieldChangeOp = "removed"
+	FieldRenamed FieldChangeOp = "renamed"
+	FieldChanged FieldChangeOp = "changed"
+)
+
+// FieldChange is one field-level difference between the input artifact and a
+// migrated artifact. Old and New are only set for scalar values.
+type FieldChange struct {
+	Op   FieldChangeOp `json:"op"`
+	Path string        `json:"path"`
+	// From is the original path of a renamed field.
+	From string `json:"from,omitempty"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// renameSimilarity is the share of leaf values two subtrees must have in
+// common for a removed and an added field to be reported as a rename.
+const renameSimilarity = 0.5
+
+// diffArtifact fills in the field-level and unified diffs between the input
+// content and a migrated artifact.
+func diffArtifact(input string, a *MigratedArtifact) error {
+	var before, after any
+	if err := yaml.UnmarshalWithOptions([]byte(input), &before, yaml.UseOrderedMap()); err != nil {
+		return fmt.Errorf("reading input for diff: %w", err)
+	}
+	if err := yaml.UnmarshalWithOptions([]byte(a.Content), &after, yaml.UseOrderedMap()); err != nil {
+		return fmt.Errorf("reading migrated %s for diff: %w", a.Type, err)
+	}
+	a.Diff = diffFields(before, after)
+	return unifiedDiff(input, "input", a)
+}
+
+// diffExtracted marks an artifact extracted from the input and fills in its
+// unified diff against an empty file. Its fields are not diffed, since it
+// does not share the shape of the input it was split out of.
+func diffExtracted(a *MigratedArtifact) error {
+	a.Extracted = true
+	return unifiedDiff("", "/dev/null", a)
+}
+
+// unifiedDiff fills in the unified diff of from, named fromFile, against the
+// content of a migrated artifact.
+func unifiedDiff(from, fromFile string, a *MigratedArtifact) error {
+	var lines []string
+	if from != "" {
+		lines = difflib.SplitLines(from)
+	}
+	unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines,
+		B:        difflib.SplitLines(a.Content),
+		FromFile: fromFile,
+		ToFile:   a.SuggestedFilename,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("diffing migrated %s: %w", a.Type, err)
+	}
+	a.UnifiedDiff = unified
+	return nil
+}
+
+// diffFields returns the field-level changes between two YAML documents
+// decoded with ordered maps, in document order.
+func diffFields(before, after any) []FieldChange {
+	var changes []FieldChange
+	diffValue("", before, after, &changes)
+	return changes
+}
+
+func diffValue(path string, before, after any, changes *[]FieldChange) {
+	switch b := before.(type) {
+	case yaml.MapSlice:
+		if a, ok := after.(yaml.MapSlice); ok {
+			diffMaps(path, b, a, changes)
+			return
+		}
+	case []any:
+		if a, ok := after.([]any); ok {
+			for i := 0; i < len(b) || i < len(a); i++ {
+				elemPath := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(a):
+					*changes = append(*changes, FieldChange{Op: FieldRemoved, Path: elemPath, Old: scalar(b[i])})
+				case i >= len(b):
+					*changes = append(*changes, FieldChange{Op: FieldAdded, Path: elemPath, New: scalar(a[i])})
+				default:
+					diffValue(elemPath, b[i], a[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, FieldChange{Op: FieldChanged, Path: path, Old: scalar(before), New: scalar(after)})
+	}
+}
+
+// diffMaps diffs two mappings key by key. A removed key is reported as
+// renamed when an added key holds a similar value.
+func diffMaps(path string, before, after yaml.MapSlice, changes *[]FieldChange) {
+	match := matchKeys(before, after)
+	matchedBy := make(map[int]int, len(match))
+	for i, j := range match {
+		if j >= 0 {
+			matchedBy[j] = i
+		}
+	}
+
+	for j, item := range before {
+		key := fmt.Sprint(item.Key)
+		i, ok := matchedBy[j]
+		if !ok {
+			*changes = append(*changes, FieldChange{Op: FieldRemoved, Path: joinPath(path, key), Old: scalar(item.Value)})
+			continue
+		}
+		newKey := fmt.Sprint(after[i].Key)
+		if newKey != key {
+			*changes = append(*changes, FieldChange{Op: FieldRenamed, Path: joinPath(path, newKey), From: joinPath(path, key)})
+		}
+		diffValue(joinPath(path, newKey), item.Value, after[i].Value, changes)
+	}
+	for i, item := range after {
+		if match[i] < 0 {
+			*changes = append(*changes, FieldChange{Op: FieldAdded, Path: joinPath(path, fmt.Sprint(item.Key)), New: scalar(item.Value)})
+		}
+	}
+}
+
+// matchKeys pairs each key of after with the key of before it came from:
+// the same key, or a removed key with a similar value when it was renamed.
+// Keys of after with no counterpart are matched to -1.
+func matchKeys(before, after yaml.MapSlice) []int {
+	match := make([]int, len(after))
+	afterKeys := make(map[string]int, len(after))
+	for i, item := range after {
+		match[i] = -1
+		afterKeys[fmt.Sprint(item.Key)] = i
+	}
+	beforeKeys := make(map[string]bool, len(before))
+	for j, item := range before {
+		key := fmt.Sprint(item.Key)
+		beforeKeys[key] = true
+		if i, ok := afterKeys[key]; ok {
+			match[i] = j
+		}
+	}
+
+	for j, item := range before {
+		if _, ok := afterKeys[fmt.Sprint(item.Key)]; ok {
+			continue
+		}
+		for i, candidate := range after {
+			if match[i] >= 0 || beforeKeys[fmt.Sprint(candidate.Key)] || !similar(item.Value, candidate.Value) {
+				continue
+			}
+			match[i] = j
+			break
+		}
+	}
+	return match
+}
+
+// similar reports whether two values are alike enough to be the same field
+// under a new name: equal scalars, or collections of the same kind sharing
+// most of their leaf values.
+func similar(before, after any) bool {
+	if reflect.DeepEqual(before, after) {
+		return true
+	}
+	if reflect.TypeOf(before) != reflect.TypeOf(after) || scalar(before) != nil {
+		return false
+	}
+	b, a := leafValues(before, nil), leafValues(after, nil)
+	if len(b)+len(a) == 0 {
+		return false
+	}
+	counts := make(map[string]int, len(b))
+	for _, v := range b {
+		counts[v]++
+	}
+	common := 0
+	for _, v := range a {
+		if counts[v] > 0 {
+			counts[v]--
+			common++
+		}
+	}
+	return float64(2*common)/float64(len(b)+len(a)) >= renameSimilarity
+}
+
+// leafValues appends the scalar values below value.
+func leafValues(value any, leaves []string) []string {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			leaves = leafValues(item.Value, leaves)
+		}
+	case []any:
+		for _, e := range v {
+			leaves = leafValues(e, leaves)
+		}
+	default:
+		leaves = append(leaves, fmt.Sprint(v))
+	}
+	return leaves
+}
+
+// scalar returns value when it is not a mapping or list, and nil otherwise.
+func scalar(value any) any {
+	switch value.(type) {
+	case yaml.MapSlice, []any:
+		return nil
+	}
+	return value
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []FieldChange
+	}{
+		{
+			name:   "identical",
+			before: "a: 1\nb: [x, y]\n",
+			after:  "a: 1\nb: [x, y]\n",
+			want:   nil,
+		},
+		{
+			name:   "changed scalar",
+			before: "metadata:\n  gemara-version: 0.20.0\n",
+			after:  "metadata:\n  gemara-version: v1.0.0\n",
+			want: []FieldChange{
+				{Op: FieldChanged, Path: "metadata.gemara-version", Old: "0.20.0", New: "v1.0.0"},
+			},
+		},
+		{
+			name:   "added and removed",
+			before: "title: t\nobsolete: true\n",
+			after:  "title: t\nstate: Active\n",
+			want: []FieldChange{
+				{Op: FieldRemoved, Path: "obsolete", Old: true},
+				{Op: FieldAdded, Path: "state", New: "Active"},
+			},
+		},
+		{
+			name:   "renamed scalar",
+			before: "document-type: Standard\n",
+			after:  "type: Standard\n",
+			want: []FieldChange{
+				{Op: FieldRenamed, Path: "type", From: "document-type"},
+			},
+		},
+		{
+			name: "renamed list with nested changes",
+			before: `families:
+  - id: F1
+    title: Family one
+    description: First
+controls:
+  - id: C1
+    family: F1
+`,
+			after: `groups:
+  - id: F1
+    title: Family one
+    description: First
+controls:
+  - id: C1
+    group: F1
+    state: Active
+`,
+			want: []FieldChange{
+				{Op: FieldRenamed, Path: "groups", From: "families"},
+				{Op: FieldRenamed, Path: "controls[0].group", From: "controls[0].family"},
+				{Op: FieldAdded, Path: "controls[0].state", New: "Active"},
+			},
+		},
+		{
+			name:   "dissimilar collections are not renamed",
+			before: "capabilities:\n  - id: CAP01\n    title: One\n",
+			after:  "groups:\n  - id: default\n    title: Default\n",
+			want: []FieldChange{
+				{Op: FieldRemoved, Path: "capabilities"},
+				{Op: FieldAdded, Path: "groups"},
+			},
+		},
+		{
+			name:   "list grows and shrinks",
+			before: "a: [x, y, z]\nb: [x]\n",
+			after:  "a: [x]\nb: [x, y]\n",
+			want: []FieldChange{
+				{Op: FieldRemoved, Path: "a[1]", Old: "y"},
+				{Op: FieldRemoved, Path: "a[2]", Old: "z"},
+				{Op: FieldAdded, Path: "b[1]", New: "y"},
+			},
+		},
+		{
+			name:   "kind change",
+			before: "imports:\n  threats: [EX]\n",
+			after:  "imports: [EX]\n",
+			want: []FieldChange{
+				{Op: FieldChanged, Path: "imports"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after any
+			require.NoError(t, yaml.UnmarshalWithOptions([]byte(tt.before), &before, yaml.UseOrderedMap()))
+			require.NoError(t, yaml.UnmarshalWithOptions([]byte(tt.after), &after, yaml.UseOrderedMap()))
+			assert.Equal(t, tt.want, diffFields(before, after))
+		})
+	}
+}
+
+func TestDiffArtifact(t *testing.T) {
+	artifact := MigratedArtifact{
+		Type:              "ControlCatalog",
+		SuggestedFilename: "controls.yaml",
+		Content:           "metadata:\n  id: EX\n  gemara-version: v1.0.0\ntitle: Example\n",
+	}
+	require.NoError(t, diffArtifact("metadata:\n  id: EX\n  gemara-version: 0.20.0\ntitle: Example", &artifact))
+
+	assert.Equal(t, []FieldChange{
+		{Op: FieldChanged, Path: "metadata.gemara-version", Old: "0.20.0", New: "v1.0.0"},
+	}, artifact.Diff)
+	assert.Contains(t, artifact.UnifiedDiff, "--- input\n+++ controls.yaml\n")
+	assert.Contains(t, artifact.UnifiedDiff, "-  gemara-version: 0.20.0\n+  gemara-version: v1.0.0\n")
+}
+
+func TestDiffExtracted(t *testing.T) {
+	artifact := MigratedArtifact{
+		Type:              "CapabilityCatalog",
+		SuggestedFilename: "capabilities.yaml",
+		Content:           "metadata:\n  id: EX\ntitle: Capabilities\n",
+	}
+	require.NoError(t, diffExtracted(&artifact))
+
+	assert.True(t, artifact.Extracted)
+	assert.Empty(t, artifact.Diff)
+	assert.Contains(t, artifact.UnifiedDiff, "--- /dev/null\n+++ capabilities.yaml\n@@ -0,0 +1,")
+	assert.NotContains(t, artifact.UnifiedDiff, "\n-")
+	assert.Contains(t, artifact.UnifiedDiff, "+metadata:\n+  id: EX\n+title: Capabilities\n")
+}
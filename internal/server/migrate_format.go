@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// preserveFormatting renders migrated, a YAML document produced by a
+// migration, in the layout of source: fields keep their source order,
+// untouched fields are copied verbatim with their comments, anchors and
+// scalar styles, and renamed or changed fields keep their comments. Fields
+// the migration added are rendered as migrated. If source cannot be reused,
+// migrated is returned unchanged, so the result always decodes to the same
+// value as migrated.
+func preserveFormatting(source, migrated string) string {
+	formatted, err := formatLike(source, migrated)
+	if err != nil {
+		slog.Debug("not preserving source formatting", "error", err)
+		return migrated
+	}
+	return formatted
+}
+
+func formatLike(source, migrated string) (string, error) {
+	file, err := parser.ParseBytes([]byte(source), parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	if len(file.Docs) != 1 || file.Docs[0].Body == nil {
+		return "", fmt.Errorf("source is not a single YAML document")
+	}
+
+	var before, after any
+	if err := yaml.UnmarshalWithOptions([]byte(source), &before, yaml.UseOrderedMap()); err != nil {
+		return "", err
+	}
+	if err := yaml.UnmarshalWithOptions([]byte(migrated), &after, yaml.UseOrderedMap()); err != nil {
+		return "", err
+	}
+	b, ok := before.(yaml.MapSlice)
+	a, ok2 := after.(yaml.MapSlice)
+	if !ok || !ok2 || len(mappingValues(file.Docs[0].Body)) == 0 {
+		return "", fmt.Errorf("document root is not a mapping")
+	}
+
+	var f formatter
+	lines := f.mapping(file.Docs[0].Body, b, a, 0)
+	if f.err != nil {
+		return "", f.err
+	}
+	formatted := strings.Join(lines, "\n") + "\n"
+
+	var got, want any
+	if err := yaml.Unmarshal([]byte(formatted), &got); err != nil {
+		return "", fmt.Errorf("formatted document is invalid: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(migrated), &want); err != nil {
+		return "", err
+	}
+	if !reflect.DeepEqual(got, want) {
+		return "", fmt.Errorf("formatted document differs from migrated output")
+	}
+	return formatted, nil
+}
+
+// formatter renders a migrated value as lines of YAML, reusing the source
+// AST node each value came from. The first rendering error is kept in err.
+type formatter struct {
+	err error
+}
+
+// mapping renders after, the migrated form of the block mapping node whose
+// value is before, with its keys at indent.
+func (f *formatter) mapping(node ast.Node, before, after yaml.MapSlice, indent int) []string {
+	values := make(map[string]*ast.MappingValueNode)
+	for _, mv := range mappingValues(node) {
+		values[mv.Key.GetToken().Value] = mv
+	}
+
+	match := matchKeys(before, after)
+	var lines []string
+	for _, i := range keyOrder(match) {
+		item := after[i]
+		key := fmt.Sprint(item.Key)
+		if match[i] < 0 {
+			lines = append(lines, f.fresh(yaml.MapSlice{item}, indent)...)
+			continue
+		}
+		source := before[match[i]]
+		mv := values[fmt.Sprint(source.Key)]
+		if mv == nil {
+			lines = append(lines, f.fresh(yaml.MapSlice{item}, indent)...)
+			continue
+		}
+		lines = append(lines, f.pair(mv, key, source.Value, item.Value, indent)...)
+	}
+	if m, ok := node.(*ast.MappingNode); ok && m.FootComment != nil {
+		lines = append(lines, reindent(m.FootComment.StringWithSpace(indent), indent, indent)...)
+	}
+	return lines
+}
+
+// pair renders the field key with value after, migrated from the source
+// field mv whose value was before.
+func (f *formatter) pair(mv *ast.MappingValueNode, key string, before, after any, indent int) []string {
+	col := mv.Key.GetToken().Position.Column - 1
+	oldKey := mv.Key.GetToken().Value
+	if reflect.DeepEqual(before, after) {
+		lines := reindent(mv.String(), col, indent)
+		if key == oldKey {
+			return lines
+		}
+		prefix := strings.Repeat(" ", indent) + mv.Key.String() + ":"
+		for i, line := range lines {
+			if strings.HasPrefix(line, prefix) {
+				lines[i] = strings.Repeat(" ", indent) + key + ":" + strings.TrimPrefix(line, prefix)
+				return lines
+			}
+		}
+	}
+
+	var lines []string
+	if mv.Comment != nil {
+		lines = append(lines, reindent(mv.Comment.StringWithSpace(col), col, indent)...)
+	} else if strings.HasPrefix(mv.String(), "\n") {
+		lines = append(lines, "")
+	}
+
+	value, anchor := mv.Value, ""
+	if a, ok := value.(*ast.AnchorNode); ok {
+		value, anchor = a.Value, " &"+a.Name.String()
+	}
+	keyLine := strings.Repeat(" ", indent) + key + ":" + anchor
+
+	switch a := after.(type) {
+	case yaml.MapSlice:
+		if b, ok := before.(yaml.MapSlice); ok && len(a) > 0 && len(mappingValues(value)) > 0 {
+			child := indent + mappingValues(value)[0].Key.GetToken().Position.Column - 1 - col
+			return append(append(lines, keyLine), f.mapping(value, b, a, child)...)
+		}
+	case []any:
+		if seq, ok := value.(*ast.SequenceNode); ok && !seq.IsFlowStyle && len(a) > 0 {
+			if b, ok := before.([]any); ok {
+				child := indent + seq.Start.Position.Column - 1 - col
+				return append(append(lines, keyLine), f.sequence(seq, b, a, child)...)
+			}
+		}
+	}
+
+	rendered := f.fresh(yaml.MapSlice{{Key: key, Value: after}}, indent)
+	if _, ok := value.(ast.ScalarNode); ok && value.GetComment() != nil && len(rendered) == 1 {
+		rendered[0] += " " + value.GetComment().String()
+	}
+	return append(lines, rendered...)
+}
+
+// sequence renders after, the migrated form of the block sequence seq whose
+// value is before, with its entries at indent.
+func (f *formatter) sequence(seq *ast.SequenceNode, before, after []any, indent int) []string {
+	space := strings.Repeat(" ", indent)
+	col := seq.Start.Position.Column - 1
+	var lines []string
+	if seq.Comment != nil {
+		lines = append(lines, reindent(seq.Comment.StringWithSpace(col), col, indent)...)
+	}
+	for i, a := range after {
+		if i >= len(before) || i >= len(seq.Values) {
+			lines = append(lines, f.fresh([]any{a}, indent)...)
+			continue
+		}
+		if len(seq.ValueHeadComments) == len(seq.Values) && seq.ValueHeadComments[i] != nil {
+			lines = append(lines, reindent(seq.ValueHeadComments[i].StringWithSpace(col), col, indent)...)
+		}
+
+		elem := seq.Values[i]
+		var body []string
+		bm, isMap := before[i].(yaml.MapSlice)
+		am, _ := after[i].(yaml.MapSlice)
+		switch {
+		case reflect.DeepEqual(before[i], a):
+			body = reindentEntry(elem, indent+2)
+		case isMap && len(am) > 0 && len(mappingValues(elem)) > 0:
+			body = f.mapping(elem, bm, am, indent+2)
+		default:
+			lines = append(lines, f.fresh([]any{a}, indent)...)
+			continue
+		}
+
+		for len(body) > 0 && strings.TrimSpace(body[0]) == "" {
+			lines = append(lines, "")
+			body = body[1:]
+		}
+		if len(body) == 0 {
+			lines = append(lines, f.fresh([]any{a}, indent)...)
+			continue
+		}
+		body[0] = space + "- " + strings.TrimLeft(body[0], " ")
+		lines = append(lines, body...)
+	}
+	if seq.FootComment != nil {
+		lines = append(lines, reindent(seq.FootComment.StringWithSpace(col), col, indent)...)
+	}
+	return lines
+}
+
+// fresh renders a value that has no source text, indented by indent.
+func (f *formatter) fresh(value any, indent int) []string {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		if f.err == nil {
+			f.err = err
+		}
+		return nil
+	}
+	return reindent(strings.TrimSuffix(string(data), "\n"), 0, indent)
+}
+
+// reindentEntry returns the text of a sequence entry with its first line
+// unindented and the rest moved to indent.
+func reindentEntry(elem ast.Node, indent int) []string {
+	lines := strings.Split(elem.String(), "\n")
+	for len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	col := indent
+	if mvs := mappingValues(elem); len(mvs) > 0 {
+		col = mvs[0].Key.GetToken().Position.Column - 1
+	} else if tok := elem.GetToken(); tok != nil {
+		col = tok.Position.Column - 1 + tok.Position.IndentNum
+	}
+	rest := reindent(strings.Join(lines[1:], "\n"), col, indent)
+	if len(lines) == 1 {
+		rest = nil
+	}
+	return append([]string{strings.TrimLeft(lines[0], " ")}, rest...)
+}
+
+// reindent splits text into lines and moves each from column from to to.
+func reindent(text string, from, to int) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		if to > from {
+			lines[i] = strings.Repeat(" ", to-from) + line
+			continue
+		}
+		trim := from - to
+		if lead := len(line) - len(strings.TrimLeft(line, " ")); lead < trim {
+			trim = lead
+		}
+		lines[i] = line[trim:]
+	}
+	return lines
+}
+
+// keyOrder returns the indices of the migrated keys in output order: keys
+// carried over from the source keep its order, and added keys follow the key
+// they follow in the migrated document.
+func keyOrder(match []int) []int {
+	var order []int
+	for i, m := range match {
+		if m >= 0 {
+			order = append(order, i)
+		}
+	}
+	slices.SortFunc(order, func(a, b int) int { return cmp.Compare(match[a], match[b]) })
+
+	for i, m := range match {
+		if m >= 0 {
+			continue
+		}
+		pos := slices.Index(order, i-1) + 1
+		order = slices.Insert(order, pos, i)
+	}
+	return order
+}
+
+// mappingValues returns the fields of a block mapping node.
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		if !n.IsFlowStyle {
+			return n.Values
+		}
+	case *ast.MappingValueNode:
+		if !n.IsFlowStyle {
+			return []*ast.MappingValueNode{n}
+		}
+	}
+	return nil
+}
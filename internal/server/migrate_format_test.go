@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreserveFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		migrated string
+		want     string
+	}{
+		{
+			name: "untouched fields copied verbatim",
+			source: `# Catalog header
+metadata:
+  id: TEST # the id
+  gemara-version: "0.20.0"
+  author: &author
+    id: test
+    name: Test Author
+title: Test
+`,
+			migrated: `metadata:
+  id: TEST
+  gemara-version: v1.0.0
+  author:
+    id: test
+    name: Test Author
+title: Test
+`,
+			want: `# Catalog header
+metadata:
+  id: TEST # the id
+  gemara-version: v1.0.0
+  author: &author
+    id: test
+    name: Test Author
+title: Test
+`,
+		},
+		{
+			name: "source order kept and added fields placed after their predecessor",
+			source: `title: Test
+metadata:
+  id: TEST
+`,
+			migrated: `metadata:
+  id: TEST
+  type: ControlCatalog
+title: Test
+state: Active
+`,
+			want: `title: Test
+state: Active
+metadata:
+  id: TEST
+  type: ControlCatalog
+`,
+		},
+		{
+			name: "renamed fields keep comments and nested formatting",
+			source: `title: Test
+
+# families comment
+families:
+  - id: F1 # family one
+    description: |
+      Multi
+      line
+controls:
+    # first control
+    - id: C1
+      family: F1  # fam ref
+      objective: >-
+        folded text
+        here
+`,
+			migrated: `title: Test
+groups:
+- id: F1
+  description: |
+    Multi
+    line
+controls:
+- id: C1
+  objective: folded text here
+  group: F1
+  state: Active
+`,
+			want: `title: Test
+
+# families comment
+groups:
+  - id: F1 # family one
+    description: |
+      Multi
+      line
+controls:
+    # first control
+    - id: C1
+      group: F1 # fam ref
+      state: Active
+      objective: >-
+        folded text
+        here
+`,
+		},
+		{
+			name: "list entries added and removed",
+			source: `applicability:
+  - a # first
+  - b
+  - c
+extra:
+  - x
+`,
+			migrated: `applicability:
+- a
+- b
+extra:
+- x
+- id: new
+`,
+			want: `applicability:
+  - a # first
+  - b
+extra:
+  - x
+  - id: new
+`,
+		},
+		{
+			name:     "flow collections re-rendered when changed",
+			source:   "tags: [a, b]\nmeta: {id: X}\n",
+			migrated: "tags:\n- a\n- c\nmeta:\n  id: Y\n",
+			want:     "tags:\n- a\n- c\nmeta:\n  id: \"Y\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := preserveFormatting(tt.source, tt.migrated)
+			assert.Equal(t, tt.want, got)
+
+			var gotValue, wantValue any
+			require.NoError(t, yaml.Unmarshal([]byte(got), &gotValue))
+			require.NoError(t, yaml.Unmarshal([]byte(tt.migrated), &wantValue))
+			assert.Equal(t, wantValue, gotValue, "formatted document must decode to the migrated value")
+		})
+	}
+}
+
+func TestPreserveFormattingFallback(t *testing.T) {
+	migrated := "metadata:\n  id: TEST\n"
+	for name, source := range map[string]string{
+		"invalid source":     "metadata: [unclosed",
+		"non-mapping root":   "- a\n- b\n",
+		"multiple documents": "a: 1\n---\nb: 2\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, migrated, preserveFormatting(source, migrated))
+		})
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3, 0}, keyOrder([]int{1, 0, -1, -1}))
+	assert.Equal(t, []int{0, 1}, keyOrder([]int{-1, 0}))
+	assert.Equal(t, []int{2, 0, 1}, keyOrder([]int{1, -1, 0}))
+}
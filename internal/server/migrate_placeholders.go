@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// placeholderValue marks a field the migration could not fill in from the
+// input artifact.
+const placeholderValue = "REPLACE ME"
+
+// MigrationTODO is a placeholder left in a migrated artifact that must be
+// given a real value before the artifact is used.
+type MigrationTODO struct {
+	Artifact string `json:"artifact"`
+	Path     string `json:"path"`
+	// Override is the overrides key that supplies this value.
+	Override    string `json:"override"`
+	Line        int    `json:"line,omitempty"`
+	Description string `json:"description"`
+}
+
+// placeholderDescriptions describes what belongs in each placeholder field,
+// keyed by path suffix with list indices removed. The longest matching
+// suffix wins.
+var placeholderDescriptions = map[string]string{
+	"metadata.id":                        "Unique identifier of this artifact, used by other artifacts to reference it",
+	"metadata.description":               "Short description of the artifact's purpose and scope",
+	"author.id":                          "Identifier of the person, organization, or tool that authored the artifact",
+	"author.name":                        "Name of the artifact's author",
+	"mapping-references[].id":            "Identifier under which the referenced artifact is mapped",
+	"mapping-references[].version":       "Version of the referenced artifact",
+	"applicability-groups[].id":          "Identifier of an applicability group, such as a maturity level or environment",
+	"applicability-groups[].title":       "Title of the applicability group",
+	"applicability-groups[].description": "Description of when the applicability group applies",
+	"title":                              "Human-readable title",
+	"groups[].id":                        "Identifier of a group that organizes the artifact's entries",
+	"groups[].title":                     "Title of the group",
+	"groups[].description":               "Description of what the group covers",
+	"group":                              "ID of the group this entry belongs to, one of the ids under groups",
+	"description":                        "Description of the entry",
+	"objective":                          "Objective the entry achieves, stated as a desired outcome",
+	"applicability[]":                    "ID of an applicability group (metadata.applicability-groups) the entry applies to",
+	"assessment-requirements[].id":       "Identifier of an assessment requirement for the control",
+	"assessment-requirements[].text":     "Verifiable condition that shows the control is in place",
+	"reference-id":                       "ID of the metadata.mapping-references entry for the referenced artifact",
+	"entries[].reference-id":             "ID of an entry in the referenced artifact",
+	"target.id":                          "Identifier of the evaluated resource",
+	"target.name":                        "Name of the evaluated resource",
+	"contacts.responsible[].name":        "Person or team responsible for carrying out the policy",
+	"contacts.accountable[].name":        "Person or team accountable for the policy",
+	"evaluation-methods[].id":            "Identifier of the accepted evaluation method",
+	"enforcement-methods[].id":           "Identifier of the accepted enforcement method",
+}
+
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// placeholderDescription describes the value that belongs at path.
+func placeholderDescription(path string) string {
+	segments := strings.Split(listIndex.ReplaceAllString(path, "[]"), ".")
+	for i := range segments {
+		if desc, ok := placeholderDescriptions[strings.Join(segments[i:], ".")]; ok {
+			return desc
+		}
+	}
+	return fmt.Sprintf("Value for %s", segments[len(segments)-1])
+}
+
+// resolvePlaceholders replaces the placeholders in a that have a value in
+// overrides and returns those that remain. An override key is a YAML path,
+// optionally prefixed with "<artifact type>:" to target a single artifact;
+// every key that supplies a value is recorded in used.
+func resolvePlaceholders(a *MigratedArtifact, overrides map[string]string, used map[string]bool) ([]MigrationTODO, error) {
+	var doc any
+	if err := yaml.UnmarshalWithOptions([]byte(a.Content), &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, fmt.Errorf("reading migrated %s: %w", a.Type, err)
+	}
+
+	var pending []string
+	replaced := false
+	doc = fillPlaceholders(doc, "", func(path string) (string, bool) {
+		for _, key := range []string{a.Type + ":" + path, path} {
+			if value, ok := overrides[key]; ok {
+				used[key] = true
+				replaced = true
+				return value, true
+			}
+		}
+		pending = append(pending, path)
+		return "", false
+	})
+
+	if replaced {
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("encoding migrated %s: %w", a.Type, err)
+		}
+		a.Content = string(data)
+	}
+	todos := make([]MigrationTODO, 0, len(pending))
+	for _, path := range pending {
+		todos = append(todos, MigrationTODO{
+			Artifact:    a.Type,
+			Path:        path,
+			Override:    a.Type + ":" + path,
+			Description: placeholderDescription(path),
+		})
+	}
+	return todos, nil
+}
+
+// locatePlaceholders sets the line of each TODO within content.
+func locatePlaceholders(content string, todos []MigrationTODO) {
+	file, err := parser.ParseBytes([]byte(content), 0)
+	if err != nil {
+		return
+	}
+	for i := range todos {
+		p, err := yaml.PathString("$." + todos[i].Path)
+		if err != nil {
+			continue
+		}
+		if node, err := p.FilterFile(file); err == nil && node != nil && node.GetToken() != nil {
+			todos[i].Line = node.GetToken().Position.Line
+		}
+	}
+}
+
+// fillPlaceholders walks value and replaces each placeholder string with the
+// value fill returns for its path, if any.
+func fillPlaceholders(value any, path string, fill func(path string) (string, bool)) any {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for i := range v {
+			v[i].Value = fillPlaceholders(v[i].Value, joinPath(path, fmt.Sprint(v[i].Key)), fill)
+		}
+	case []any:
+		for i := range v {
+			v[i] = fillPlaceholders(v[i], fmt.Sprintf("%s[%d]", path, i), fill)
+		}
+	case string:
+		if v == placeholderValue {
+			if s, ok := fill(path); ok {
+				return s
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const placeholderCatalog = `metadata:
+  id: REPLACE ME
+  type: ThreatCatalog
+  author:
+    id: REPLACE ME
+    name: REPLACE ME
+title: Example
+groups:
+  - id: REPLACE ME
+    title: Tampering
+threats:
+  - id: EX.THR01
+    group: REPLACE ME
+`
+
+func TestResolvePlaceholders(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		wantPaths []string
+		wantUsed  []string
+		contains  []string
+	}{
+		{
+			name:      "no overrides",
+			wantPaths: []string{"metadata.id", "metadata.author.id", "metadata.author.name", "groups[0].id", "threats[0].group"},
+		},
+		{
+			name: "typed and untyped overrides",
+			overrides: map[string]string{
+				"ThreatCatalog:metadata.id":  "EX",
+				"metadata.author.id":         "example-org",
+				"CapabilityCatalog:title":    "unused",
+				"ThreatCatalog:groups[0].id": "tampering",
+				"threats[0].group":           "tampering",
+			},
+			wantPaths: []string{"metadata.author.name"},
+			wantUsed:  []string{"ThreatCatalog:metadata.id", "metadata.author.id", "ThreatCatalog:groups[0].id", "threats[0].group"},
+			contains:  []string{"id: EX\n", "id: example-org", "group: tampering"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := MigratedArtifact{Type: "ThreatCatalog", Content: placeholderCatalog}
+			used := make(map[string]bool)
+			todos, err := resolvePlaceholders(&artifact, tt.overrides, used)
+			require.NoError(t, err)
+
+			var paths []string
+			for _, todo := range todos {
+				paths = append(paths, todo.Path)
+				assert.Equal(t, "ThreatCatalog", todo.Artifact)
+				assert.Equal(t, "ThreatCatalog:"+todo.Path, todo.Override)
+				assert.NotEmpty(t, todo.Description)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+
+			var usedKeys []string
+			for key := range used {
+				usedKeys = append(usedKeys, key)
+			}
+			assert.ElementsMatch(t, tt.wantUsed, usedKeys)
+			for _, s := range tt.contains {
+				assert.Contains(t, artifact.Content, s)
+			}
+			if len(tt.overrides) == 0 {
+				assert.Equal(t, placeholderCatalog, artifact.Content, "content is untouched without overrides")
+			}
+		})
+	}
+}
+
+func TestLocatePlaceholders(t *testing.T) {
+	artifact := MigratedArtifact{Type: "ThreatCatalog", Content: placeholderCatalog}
+	todos, err := resolvePlaceholders(&artifact, nil, map[string]bool{})
+	require.NoError(t, err)
+	locatePlaceholders(artifact.Content, todos)
+	lines := make(map[string]int)
+	for _, todo := range todos {
+		lines[todo.Path] = todo.Line
+	}
+	assert.Equal(t, map[string]int{
+		"metadata.id":          2,
+		"metadata.author.id":   5,
+		"metadata.author.name": 6,
+		"groups[0].id":         9,
+		"threats[0].group":     13,
+	}, lines)
+}
+
+func TestPlaceholderDescription(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"metadata.id", placeholderDescriptions["metadata.id"]},
+		{"metadata.author.id", placeholderDescriptions["author.id"]},
+		{"groups[0].id", placeholderDescriptions["groups[].id"]},
+		{"threats[3].group", placeholderDescriptions["group"]},
+		{"controls[0].assessment-requirements[1].applicability[0]", placeholderDescriptions["applicability[]"]},
+		{"threats[0].capabilities[0].entries[0].reference-id", placeholderDescriptions["entries[].reference-id"]},
+		{"threats[0].capabilities[0].reference-id", placeholderDescriptions["reference-id"]},
+		{"metadata.unknown-field", "Value for unknown-field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, placeholderDescription(tt.path))
+		})
+	}
+}
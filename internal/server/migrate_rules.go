@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/mod/modconfig"
+)
+
+// RulePack is a user-supplied CUE migration file applied after the built-in
+// transformation of every migrated artifact, for organization conventions
+// such as extra applicability groups or ID rewrites.
+//
+// A pack is a file in package migrate. It is evaluated with input (the
+// artifact being migrated), output (the artifact produced by the built-in
+// migration and any earlier packs) and the migration's extras, such as
+// target_gemara_version, filled in; fields it references must be declared,
+// e.g. "output: {...}". Its result field replaces output. A pack that does
+// not define result for an artifact leaves it unchanged.
+type RulePack struct {
+	// Name is the pack's file name without the .cue extension.
+	Name   string
+	Source string
+}
+
+// LoadRulePacks reads every .cue file in dir as a rule pack. Packs are
+// applied in file name order.
+func LoadRulePacks(dir string) ([]RulePack, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading rule pack directory: %w", err)
+	}
+
+	var packs []RulePack
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".cue" {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading rule pack: %w", err)
+		}
+		f, err := parser.ParseFile(e.Name(), src)
+		if err != nil {
+			return nil, fmt.Errorf("parsing rule pack %s: %w", e.Name(), err)
+		}
+		if f.PackageName() != "migrate" {
+			return nil, fmt.Errorf("rule pack %s: must be in package migrate, got %q", e.Name(), f.PackageName())
+		}
+		packs = append(packs, RulePack{
+			Name:   strings.TrimSuffix(e.Name(), ".cue"),
+			Source: string(src),
+		})
+	}
+	if len(packs) == 0 {
+		return nil, fmt.Errorf("no .cue rule packs in %s", dir)
+	}
+	return packs, nil
+}
+
+// cueMigrator runs migration CUE in a shared context, followed by the rule
+// packs, and records the name of each pack it applied.
+type cueMigrator struct {
+	ctx *cue.Context
+	// registry resolves the migration module's dependencies; nil uses the
+	// environment-configured registry.
+	registry modconfig.Registry
+	packs    []RulePack
+	applied  []string
+}
+
+// migrate applies cueSrc and then the rule packs to inputData.
+func (m *cueMigrator) migrate(cueSrc string, inputData map[string]interface{}, extras map[string]interface{}) (string, error) {
+	content, applied, err := cueMigrate(m.ctx, m.registry, cueSrc, inputData, extras, m.packs)
+	if err != nil {
+		return "", err
+	}
+	m.applied = append(m.applied, applied...)
+	return content, nil
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/gemaraproj/go-gemara"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBuiltinMigration stands in for an embedded migration without
+// importing the Gemara module.
+const testBuiltinMigration = `package migrate
+
+input: {...}
+target_gemara_version: string
+
+output: {
+	metadata: {
+		id:               input.metadata.id
+		type:             "ControlCatalog"
+		"gemara-version": target_gemara_version
+	}
+	title: input.title
+}
+`
+
+// testApplicabilityPack appends an organization-wide applicability group.
+const testApplicabilityPack = `package migrate
+
+import "list"
+
+output: {...}
+
+result: {
+	for k, v in output if k != "metadata" {(k): v}
+	metadata: {
+		for k, v in output.metadata {(k): v}
+		"applicability-groups": list.Concat([*output.metadata."applicability-groups" | [], [{id: "internal", title: "Internal"}]])
+	}
+}
+`
+
+// testIDPack rewrites ControlCatalog IDs to the organization's prefix.
+const testIDPack = `package migrate
+
+import "strings"
+
+input: {...}
+output: {...}
+target_gemara_version: string
+
+if output.metadata.type == "ControlCatalog" {
+	result: {
+		for k, v in output if k != "metadata" {(k): v}
+		metadata: {
+			for k, v in output.metadata if k != "id" {(k): v}
+			id: "ORG-" + strings.ToUpper(input.metadata.id) + "-" + target_gemara_version
+		}
+	}
+}
+`
+
+// testSkippedPack only applies to ThreatCatalogs.
+const testSkippedPack = `package migrate
+
+output: {...}
+
+if output.metadata.type == "ThreatCatalog" {
+	result: output
+}
+`
+
+func TestLoadRulePacks(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantNames   []string
+		errContains string
+	}{
+		{
+			name: "packs in file name order",
+			files: map[string]string{
+				"20-ids.cue":           testIDPack,
+				"10-applicability.cue": testApplicabilityPack,
+				"README.md":            "not a pack",
+				"nested/ignored.cue":   testSkippedPack,
+			},
+			wantNames: []string{"10-applicability", "20-ids"},
+		},
+		{
+			name:        "wrong package",
+			files:       map[string]string{"rules.cue": "package rules\n\nresult: output\n"},
+			errContains: "must be in package migrate",
+		},
+		{
+			name:        "syntax error",
+			files:       map[string]string{"rules.cue": "package migrate\n\nresult: {\n"},
+			errContains: "parsing rule pack rules.cue",
+		},
+		{
+			name:        "no packs",
+			files:       map[string]string{"README.md": "not a pack"},
+			errContains: "no .cue rule packs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+			}
+
+			packs, err := LoadRulePacks(dir)
+			if tt.errContains != "" {
+				require.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, p := range packs {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	_, err := LoadRulePacks(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "reading rule pack directory")
+}
+
+func TestCueMigrateRulePacks(t *testing.T) {
+	input := map[string]interface{}{
+		"metadata": map[string]interface{}{"id": "ex", "type": "ControlCatalog", "gemara-version": "0.20.0"},
+		"title":    "Example",
+	}
+	extras := map[string]interface{}{"target_gemara_version": "v1.0.0"}
+
+	tests := []struct {
+		name        string
+		packs       []RulePack
+		wantApplied []string
+		contains    []string
+		errContains string
+	}{
+		{
+			name:     "no packs",
+			contains: []string{"id: ex\n", "title: Example"},
+		},
+		{
+			name: "packs chain in order",
+			packs: []RulePack{
+				{Name: "applicability", Source: testApplicabilityPack},
+				{Name: "skipped", Source: testSkippedPack},
+				{Name: "ids", Source: testIDPack},
+			},
+			wantApplied: []string{"applicability", "ids"},
+			contains:    []string{"id: ORG-EX-v1.0.0", "- id: internal", "title: Example"},
+		},
+		{
+			name:        "conflicting result",
+			packs:       []RulePack{{Name: "broken", Source: "package migrate\n\noutput: {...}\nresult: output & {title: 1}\n"}},
+			errContains: "rule pack \"broken\"",
+		},
+		{
+			name:        "undeclared reference",
+			packs:       []RulePack{{Name: "undeclared", Source: "package migrate\n\nresult: outptu\n"}},
+			errContains: "rule pack \"undeclared\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, applied, err := cueMigrate(cuecontext.New(), nil, testBuiltinMigration, input, extras, tt.packs)
+			if tt.errContains != "" {
+				require.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantApplied, applied)
+			for _, s := range tt.contains {
+				assert.Contains(t, content, s)
+			}
+		})
+	}
+}
+
+func TestMigrateChainRulePacks(t *testing.T) {
+	steps := []migrationStep{{
+		artifactType: gemara.ControlCatalogArtifact,
+		from:         "v0",
+		to:           gemaraV1,
+		migrate:      simpleMigration(testBuiltinMigration, gemara.ControlCatalogArtifact, "controls.yaml"),
+	}}
+	root := map[string]interface{}{
+		"metadata": map[string]interface{}{"id": "ex", "type": "ControlCatalog", "gemara-version": "0.20.0"},
+		"title":    "Example",
+	}
+	m := &cueMigrator{ctx: cuecontext.New(), packs: []RulePack{{Name: "ids", Source: testIDPack}}}
+
+	output, err := migrateChain(m, steps, gemara.ControlCatalogArtifact, root, "0.20.0", gemaraV1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ids"}, output.RulePacks)
+	require.Len(t, output.Hops, 1)
+	assert.Contains(t, output.Hops[0].Changes, `Applied rule pack "ids"`)
+	require.Len(t, output.Artifacts, 1)
+	assert.Contains(t, output.Artifacts[0].Content, "id: ORG-EX-v1.0.0")
+}
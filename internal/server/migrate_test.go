@@ -4,8 +4,16 @@ package server
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"cuelang.org/go/cue"
+	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
 	gemara "github.com/gemaraproj/go-gemara"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -68,11 +76,29 @@ func TestMigrateGemaraArtifact(t *testing.T) {
 		{
 			name: "unsupported artifact type",
 			input: InputMigrateGemaraArtifact{
-				ArtifactContent: "metadata:\n  type: Policy\n  gemara-version: \"0.1.0\"",
+				ArtifactContent: "metadata:\n  type: RiskCatalog\n  gemara-version: \"0.1.0\"",
 			},
 			wantErr:     true,
 			errContains: "unsupported",
 		},
+		{
+			name: "invalid target_version rejected",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0ControlCatalog,
+				TargetVersion:   "latest",
+			},
+			wantErr:     true,
+			errContains: "invalid target_version",
+		},
+		{
+			name: "downgrade has no migration path",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: "metadata:\n  type: ControlCatalog\n  gemara-version: \"v1.0.0\"",
+				TargetVersion:   "0.20.0",
+			},
+			wantErr:     true,
+			errContains: "no migration path",
+		},
 		{
 			name: "invalid artifact_type rejected",
 			input: InputMigrateGemaraArtifact{
@@ -114,6 +140,12 @@ func TestMigrateGemaraArtifact(t *testing.T) {
 				assert.Contains(t, cc.Content, "TEST.CAP02")
 				assert.Contains(t, cc.Content, "Security Capability Catalog",
 					"title should replace 'Threat Catalog' with 'Capability Catalog'")
+
+				assert.False(t, tc.Extracted)
+				assert.NotEmpty(t, tc.Diff)
+				assert.True(t, cc.Extracted, "capabilities are extracted, not migrated")
+				assert.Empty(t, cc.Diff, "extracted artifacts are not diffed against the input")
+				assert.Contains(t, cc.UnifiedDiff, "--- /dev/null\n")
 			},
 		},
 		{
@@ -266,9 +298,97 @@ threats: []
 				assert.Contains(t, cc.Content, "group: test-family", "control.family should be renamed to control.group")
 				assert.NotContains(t, cc.Content, "family:", "family should not appear in migrated controls")
 				assert.Contains(t, cc.Content, "applicability-groups:", "applicability-categories should be renamed to applicability-groups")
+				require.NotNil(t, cc.Validation)
+				assert.True(t, cc.Validation.Valid, cc.Validation.Message)
+				assert.Equal(t, "#ControlCatalog", cc.Validation.Definition)
 				assert.NotContains(t, cc.Content, "applicability-categories:", "applicability-categories should not appear in migrated output")
 			},
 		},
+		{
+			name: "dry run reports diffs without content",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0ControlCatalog,
+				DryRun:          true,
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				require.Len(t, output.Artifacts, 1)
+				assert.Contains(t, output.Message, "Dry run")
+
+				cc := output.Artifacts[0]
+				assert.Empty(t, cc.Content)
+				assert.Contains(t, cc.Diff, FieldChange{Op: FieldRenamed, Path: "groups", From: "families"})
+				assert.Contains(t, cc.Diff, FieldChange{Op: FieldChanged, Path: "metadata.gemara-version", Old: "0.20.0", New: "v1.0.0"})
+				assert.Contains(t, cc.UnifiedDiff, "+++ controls.yaml")
+				assert.Contains(t, cc.UnifiedDiff, "+groups:")
+			},
+		},
+		{
+			name: "placeholders reported as TODOs",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0ThreatCatalog,
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				require.NotEmpty(t, output.TODOs)
+				assert.Contains(t, output.Message, "placeholder(s) need values")
+				var keys []string
+				for _, todo := range output.TODOs {
+					keys = append(keys, todo.Override)
+					assert.NotEmpty(t, todo.Description)
+				}
+				assert.Contains(t, keys, "ThreatCatalog:groups[0].id")
+				assert.Contains(t, keys, "ThreatCatalog:threats[0].group")
+				assert.Contains(t, keys, "CapabilityCatalog:capabilities[0].group")
+			},
+		},
+		{
+			name: "overrides fill placeholders",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0ThreatCatalog,
+				Overrides: map[string]string{
+					"ThreatCatalog:groups[0].id": "tampering",
+					"threats[0].group":           "tampering",
+				},
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				assert.Contains(t, output.Artifacts[0].Content, "id: tampering")
+				assert.Contains(t, output.Artifacts[0].Content, "group: tampering")
+				for _, todo := range output.TODOs {
+					assert.NotEqual(t, "ThreatCatalog:groups[0].id", todo.Override)
+					assert.NotEqual(t, "ThreatCatalog:threats[0].group", todo.Override)
+				}
+			},
+		},
+		{
+			name: "strict fails while placeholders remain",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0ThreatCatalog,
+				Strict:          true,
+			},
+			wantErr:     true,
+			errContains: "strict:",
+		},
+		{
+			name: "override without matching placeholder rejected",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0ThreatCatalog,
+				Overrides:       map[string]string{"metadata.id": "TEST"},
+			},
+			wantErr:     true,
+			errContains: "does not match any placeholder",
+		},
+		{
+			name: "source comments preserved",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: "# Control catalog for the test project\n" + testV0ControlCatalog,
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				require.Len(t, output.Artifacts, 1)
+				cc := output.Artifacts[0].Content
+				assert.True(t, strings.HasPrefix(cc, "# Control catalog for the test project\nmetadata:\n"), cc)
+				assert.Contains(t, cc, "groups:", "families should be renamed to groups")
+				assert.NotContains(t, cc, "families:")
+			},
+		},
 		{
 			name: "ControlCatalog with imported-controls maps to imports",
 			input: InputMigrateGemaraArtifact{
@@ -283,6 +403,79 @@ threats: []
 				assert.NotContains(t, cc.Content, "imported-controls", "old field name should not appear in output")
 			},
 		},
+		{
+			name: "GuidanceCatalog migration",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0GuidanceCatalog,
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				require.Len(t, output.Artifacts, 1)
+				assert.Contains(t, output.Message, gemara.GuidanceCatalogArtifact.String())
+
+				gc := output.Artifacts[0]
+				assert.Equal(t, gemara.GuidanceCatalogArtifact.String(), gc.Type)
+				assert.Equal(t, "guidance.yaml", gc.SuggestedFilename)
+				assert.Contains(t, gc.Content, "v1.0.0")
+				assert.Contains(t, gc.Content, "type: Standard", "document-type should be renamed to type")
+				assert.NotContains(t, gc.Content, "document-type:")
+				assert.Contains(t, gc.Content, "groups:", "families should be renamed to groups")
+				assert.NotContains(t, gc.Content, "families:")
+				assert.Contains(t, gc.Content, "group: test-family", "guideline.family should be renamed to guideline.group")
+				assert.Contains(t, gc.Content, "statements:", "guideline-parts should be renamed to statements")
+				assert.NotContains(t, gc.Content, "guideline-parts:")
+				assert.Contains(t, gc.Content, "principles:", "principle-mappings should be renamed to principles")
+				assert.Contains(t, gc.Content, "PRIN.P01")
+				assert.NotContains(t, gc.Content, "strength:", "v0-only mapping fields should be dropped")
+				assert.Contains(t, gc.Content, "state: Active", "missing state should default to Active")
+				assert.Contains(t, gc.Content, "state: Draft", "existing state should be preserved")
+				assert.Contains(t, gc.Content, "objective: REPLACE ME", "missing objective should be flagged")
+			},
+		},
+		{
+			name: "Policy migration",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0Policy,
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				require.Len(t, output.Artifacts, 1)
+				assert.Contains(t, output.Message, gemara.PolicyArtifact.String())
+
+				p := output.Artifacts[0]
+				assert.Equal(t, gemara.PolicyArtifact.String(), p.Type)
+				assert.Equal(t, "policy.yaml", p.SuggestedFilename)
+				assert.Contains(t, p.Content, "v1.0.0")
+				assert.Contains(t, p.Content, "reference-id: BASE-POLICY", "policy imports should become mappings")
+				assert.Contains(t, p.Content, "entry-id: RISKS.R01", "risk-id should become a risk mapping")
+				assert.NotContains(t, p.Content, "risk-id:")
+				assert.Contains(t, p.Content, "id: RISKS.R02", "accepted risks should be given an id")
+				assert.Contains(t, p.Content, "Compensating controls in place")
+				assert.Contains(t, p.Content, "required: false", "methods should default to not required")
+				assert.Contains(t, p.Content, "Notify the accountable contact")
+			},
+		},
+		{
+			name: "EvaluationLog migration",
+			input: InputMigrateGemaraArtifact{
+				ArtifactContent: testV0EvaluationLog,
+			},
+			validateOutput: func(t *testing.T, output OutputMigrateGemaraArtifact) {
+				require.Len(t, output.Artifacts, 1)
+				assert.Contains(t, output.Message, gemara.EvaluationLogArtifact.String())
+
+				el := output.Artifacts[0]
+				assert.Equal(t, gemara.EvaluationLogArtifact.String(), el.Type)
+				assert.Equal(t, "evaluation-log.yaml", el.SuggestedFilename)
+				assert.Contains(t, el.Content, "v1.0.0")
+				assert.Contains(t, el.Content, "evaluations:", "evaluation-set should be renamed to evaluations")
+				assert.NotContains(t, el.Content, "evaluation-set:")
+				assert.Contains(t, el.Content, "entry-id: TEST.C01", "control-id should become a control mapping")
+				assert.NotContains(t, el.Content, "control-id:")
+				assert.Contains(t, el.Content, "entry-id: TEST.C01.TR01", "requirement-id should become a requirement mapping")
+				assert.NotContains(t, el.Content, "requirement-id:")
+				assert.Contains(t, el.Content, "result: Failed", "aggregate result should reflect the failed evaluation")
+				assert.Contains(t, el.Content, "target:", "target should be added")
+			},
+		},
 		{
 			name: "missing type supplied by artifact_type",
 			input: InputMigrateGemaraArtifact{
@@ -389,9 +582,15 @@ controls:
 		},
 	}
 
+	cache := fetcher.NewCache[cue.Value](time.Hour)
+	schemas := SchemaSource{Schema: func(version string) *fetcher.CachedFetcher[cue.Value] {
+		modulePath := gemaraModuleBase + version
+		return fetcher.NewCachedFetcher[cue.Value](schema.NewCUERegistryFetcher(modulePath), cache, modulePath)
+	}}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, output, err := MigrateGemaraArtifact(context.Background(), nil, tt.input)
+			_, output, err := MigrateGemaraArtifact(context.Background(), nil, tt.input, schemas, nil)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -408,3 +607,91 @@ controls:
 		})
 	}
 }
+
+func TestMigrateGemaraArtifactFromSchemaBundle(t *testing.T) {
+	// An open stand-in for the v1 module the migrations import, so the
+	// migration resolves only against the bundle.
+	root := t.TempDir()
+	dir := filepath.Join(root, filepath.FromSlash(GemaraModulePath)+"@v1.0.0")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cue.mod"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cue.mod", "module.cue"),
+		[]byte("module: \""+GemaraModulePath+"@v1\"\nlanguage: version: \"v0.9.0\"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.cue"),
+		[]byte("package gemara\n#ControlCatalog: {...}\n#MultiEntryMapping: {...}\n"), 0o644))
+	bundle, err := schema.OpenBundle(root)
+	require.NoError(t, err)
+
+	mode, err := NewArtifactMode(1*time.Hour, WithSchemaRegistry(bundle))
+	require.NoError(t, err)
+
+	_, output, err := MigrateGemaraArtifact(context.Background(), nil,
+		InputMigrateGemaraArtifact{ArtifactContent: testV0ControlCatalog}, mode.Schemas(), nil)
+	require.NoError(t, err)
+	require.Len(t, output.Artifacts, 1)
+	assert.Contains(t, output.Artifacts[0].Content, "groups:")
+	require.NotNil(t, output.Artifacts[0].Validation)
+	assert.True(t, output.Artifacts[0].Validation.Valid, output.Artifacts[0].Validation.Message)
+}
+
+func TestMigrationSchemaVersions(t *testing.T) {
+	versions, err := MigrationSchemaVersions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0"}, versions)
+}
+
+func TestValidateMigrated(t *testing.T) {
+	const migratedSchema = `
+#ControlCatalog: {
+	metadata: {id: string, type: "ControlCatalog"}
+	title: string
+}
+`
+	artifacts := []MigratedArtifact{
+		{Type: "ControlCatalog", Content: "metadata: {id: CC, type: ControlCatalog}\ntitle: Controls\n"},
+		{Type: "ControlCatalog", Content: "metadata: {id: CC, type: ControlCatalog}\n"},
+		{Type: "CapabilityCatalog", Content: "metadata: {id: CAP}\n"},
+	}
+	var requested []string
+	schemas := SchemaSource{
+		Schema: func(version string) *fetcher.CachedFetcher[cue.Value] {
+			requested = append(requested, version)
+			if version != defaultSchemaVersion && version != "v0.20.0" {
+				return newStaticSchemaCachedFetcher("invalid: {")
+			}
+			return newStaticSchemaCachedFetcher(migratedSchema)
+		},
+		Versions: func(context.Context) ([]string, error) { return []string{"v0.19.0", "v0.20.0"}, nil },
+	}
+
+	_, version, err := validateMigrated(context.Background(), slices.Clone(artifacts), schemas, "v0.20.0")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.20.0", version, "a published target version is validated against directly")
+
+	requested = nil
+	failed, version, err := validateMigrated(context.Background(), artifacts, schemas, "1.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{defaultSchemaVersion}, requested, "an unpublished target version uses latest without fetching it")
+	assert.Equal(t, defaultSchemaVersion, version)
+	assert.Equal(t, 2, failed)
+
+	require.NotNil(t, artifacts[0].Validation)
+	assert.True(t, artifacts[0].Validation.Valid, artifacts[0].Validation.Message)
+	assert.Equal(t, "#ControlCatalog", artifacts[0].Validation.Definition)
+	assert.Equal(t, defaultSchemaVersion, artifacts[0].Validation.Version)
+
+	assert.False(t, artifacts[1].Validation.Valid)
+	assert.NotEmpty(t, artifacts[1].Validation.Errors)
+
+	assert.False(t, artifacts[2].Validation.Valid)
+	assert.Contains(t, artifacts[2].Validation.Message, "#CapabilityCatalog not found")
+}
+
+func TestValidateMigratedSchemaUnavailable(t *testing.T) {
+	artifacts := []MigratedArtifact{{Type: "ControlCatalog", Content: "metadata: {id: CC}\n"}}
+	schemas := SchemaSource{Schema: func(string) *fetcher.CachedFetcher[cue.Value] { return newStaticSchemaCachedFetcher("invalid: {") }}
+
+	failed, _, err := validateMigrated(context.Background(), artifacts, schemas, "v1.0.0")
+	require.ErrorContains(t, err, "loading schema")
+	assert.Zero(t, failed)
+	assert.Nil(t, artifacts[0].Validation)
+}
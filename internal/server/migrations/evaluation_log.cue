@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// EvaluationLog migration
+//
+// Bumps gemara-version; renames evaluation-set→evaluations,
+// control-id→control and requirement-id→requirement (as entry mappings),
+// and adds the aggregate result and the evaluated target.
+
+package migrate
+
+import (
+	"list"
+	gemara "github.com/gemaraproj/gemara@v1"
+)
+
+input: {...}
+target_gemara_version: string
+
+// _entryMapping converts a v0 single mapping or bare entry id into a v1
+// entry mapping.
+_entryMapping: {
+	in: _
+	out: {
+		if (in & string) != _|_ {
+			"reference-id": "REPLACE ME"
+			"entry-id":     in
+		}
+		if (in & string) == _|_ {
+			if in."reference-id" != _|_ {"reference-id": in."reference-id"}
+			if in."reference-id" == _|_ {"reference-id": "REPLACE ME"}
+			"entry-id": in."entry-id"
+			if in.remarks != _|_ {remarks: in.remarks}
+		}
+	}
+}
+
+_evaluations: [...]
+if input.evaluations != _|_ {_evaluations: input.evaluations}
+if input."evaluation-set" != _|_ {_evaluations: input."evaluation-set"}
+
+_results: [for e in _evaluations if e.result != _|_ {e.result}]
+
+// _aggregate is the most severe result of any evaluation.
+_aggregate: [
+	if list.Contains(_results, "Failed") {"Failed"},
+	if list.Contains(_results, "Needs Review") {"Needs Review"},
+	if list.Contains(_results, "Unknown") {"Unknown"},
+	if list.Contains(_results, "Passed") {"Passed"},
+	if list.Contains(_results, "Not Applicable") {"Not Applicable"},
+	"Not Run",
+][0]
+
+output: gemara.#EvaluationLog & {
+	metadata: {
+		if input.metadata.id != _|_ {id: input.metadata.id}
+		if input.metadata.id == _|_ {id: "REPLACE ME"}
+		type: "EvaluationLog"
+		"gemara-version": target_gemara_version
+		if input.metadata.description != _|_ {description: input.metadata.description}
+		if input.metadata.description == _|_ {description: "REPLACE ME"}
+		if input.metadata.version != _|_ {version: input.metadata.version}
+		if input.metadata.date != _|_ {date: input.metadata.date}
+		if input.metadata.author != _|_ {author: input.metadata.author}
+		if input.metadata.author == _|_ {
+			author: {
+				id:   "REPLACE ME"
+				name: "REPLACE ME"
+				type: "Human"
+			}
+		}
+		if input.metadata."mapping-references" != _|_ {"mapping-references": input.metadata."mapping-references"}
+		if input.metadata."applicability-categories" != _|_ {"applicability-groups": input.metadata."applicability-categories"}
+		if input.metadata."applicability-groups" != _|_ {"applicability-groups": input.metadata."applicability-groups"}
+	}
+
+	if input.result != _|_ {result: input.result}
+	if input.result == _|_ {result: _aggregate}
+
+	if input.target != _|_ {target: input.target}
+	if input.target == _|_ {
+		target: {
+			id:   "REPLACE ME"
+			name: "REPLACE ME"
+			type: "Software"
+		}
+	}
+
+	evaluations: [for e in _evaluations {
+		name:    e.name
+		result:  e.result
+		message: e.message
+		if e.control != _|_ {control: (_entryMapping & {in: e.control}).out}
+		if e.control == _|_ if e."control-id" != _|_ {control: (_entryMapping & {in: e."control-id"}).out}
+		"assessment-logs": [for al in e."assessment-logs" {
+			if al.requirement != _|_ {requirement: (_entryMapping & {in: al.requirement}).out}
+			if al.requirement == _|_ if al."requirement-id" != _|_ {requirement: (_entryMapping & {in: al."requirement-id"}).out}
+			if al.plan != _|_ {plan: (_entryMapping & {in: al.plan}).out}
+			if al.description != _|_ {description: al.description}
+			if al.description == _|_ {description: "REPLACE ME"}
+			result: al.result
+			if al.message != _|_ {message: al.message}
+			if al.message == _|_ {message: ""}
+			if al.applicability != _|_ {applicability: al.applicability}
+			if al.applicability == _|_ {applicability: ["REPLACE ME"]}
+			if al.steps != _|_ {steps: al.steps}
+			if al.steps == _|_ {steps: []}
+			if al."steps-executed" != _|_ {"steps-executed": al."steps-executed"}
+			start: al.start
+			if al.end != _|_ {end: al.end}
+			if al.recommendation != _|_ {recommendation: al.recommendation}
+			if al."confidence-level" != _|_ {"confidence-level": al."confidence-level"}
+		}]
+	}]
+}
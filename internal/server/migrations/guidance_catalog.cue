@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// GuidanceCatalog migration
+//
+// Bumps gemara-version; renames document-type→type, families→groups,
+// guideline.family→guideline.group, guideline-parts→statements,
+// principle-mappings→principles, vector-mappings→vectors and
+// imported-guidelines→imports; defaults guideline state to Active.
+
+package migrate
+
+import gemara "github.com/gemaraproj/gemara@v1"
+
+input: {...}
+target_gemara_version: string
+
+// _multiEntryMapping drops v0-only mapping fields such as strength.
+_multiEntryMapping: {
+	in: {...}
+	out: {
+		"reference-id": in."reference-id"
+		entries: [for e in in.entries {
+			"reference-id": e."reference-id"
+			if e.remarks != _|_ {remarks: e.remarks}
+		}]
+		if in.remarks != _|_ {remarks: in.remarks}
+	}
+}
+
+output: gemara.#GuidanceCatalog & {
+	metadata: {
+		if input.metadata.id != _|_ {id: input.metadata.id}
+		if input.metadata.id == _|_ {id: "REPLACE ME"}
+		type: "GuidanceCatalog"
+		"gemara-version": target_gemara_version
+		if input.metadata.description != _|_ {description: input.metadata.description}
+		if input.metadata.description == _|_ {description: "REPLACE ME"}
+		if input.metadata.version != _|_ {version: input.metadata.version}
+		if input.metadata.author != _|_ {author: input.metadata.author}
+		if input.metadata.author == _|_ {
+			author: {
+				id:   "REPLACE ME"
+				name: "REPLACE ME"
+				type: "Human"
+			}
+		}
+		if input.metadata."mapping-references" != _|_ {"mapping-references": input.metadata."mapping-references"}
+		if input.metadata."applicability-categories" != _|_ {"applicability-groups": input.metadata."applicability-categories"}
+		if input.metadata."applicability-groups" != _|_ {"applicability-groups": input.metadata."applicability-groups"}
+	}
+
+	if input.title != _|_ {title: input.title}
+	if input.title == _|_ {title: "REPLACE ME"}
+
+	if input."document-type" != _|_ {type: input."document-type"}
+	if input.type != _|_ {type: input.type}
+
+	if input."front-matter" != _|_ {"front-matter": input."front-matter"}
+
+	if input.families != _|_ {
+		groups: input.families
+	}
+	if input.groups != _|_ {
+		groups: input.groups
+	}
+
+	if input.extends != _|_ {extends: input.extends}
+
+	if input."imported-guidelines" != _|_ {
+		imports: [for m in input."imported-guidelines" {(_multiEntryMapping & {in: m}).out}]
+	}
+	if input.imports != _|_ {
+		if input.imports.guidelines != _|_ {
+			imports: [for m in input.imports.guidelines {(_multiEntryMapping & {in: m}).out}]
+		}
+		if input.imports.guidelines == _|_ {
+			imports: [for m in input.imports {(_multiEntryMapping & {in: m}).out}]
+		}
+	}
+
+	if input.guidelines != _|_ {
+		guidelines: [for g in input.guidelines {
+			id:    g.id
+			title: g.title
+			if g.objective != _|_ {objective: g.objective}
+			if g.objective == _|_ {objective: "REPLACE ME"}
+			if g.family != _|_ {group: g.family}
+			if g.group != _|_ {group: g.group}
+			if g.family == _|_ if g.group == _|_ {group: "REPLACE ME"}
+			if g.recommendations != _|_ {recommendations: g.recommendations}
+			if g.extends != _|_ {extends: g.extends}
+			if g.applicability != _|_ {applicability: g.applicability}
+			if g.rationale != _|_ {rationale: g.rationale}
+			if g."guideline-parts" != _|_ {statements: g."guideline-parts"}
+			if g.statements != _|_ {statements: g.statements}
+			if g."principle-mappings" != _|_ {
+				principles: [for m in g."principle-mappings" {(_multiEntryMapping & {in: m}).out}]
+			}
+			if g.principles != _|_ {
+				principles: [for m in g.principles {(_multiEntryMapping & {in: m}).out}]
+			}
+			if g."vector-mappings" != _|_ {
+				vectors: [for m in g."vector-mappings" {(_multiEntryMapping & {in: m}).out}]
+			}
+			if g.vectors != _|_ {
+				vectors: [for m in g.vectors {(_multiEntryMapping & {in: m}).out}]
+			}
+			if g."see-also" != _|_ {"see-also": g."see-also"}
+			if g.state != _|_ {state: g.state}
+			if g.state == _|_ {state: "Active"}
+			if g."replaced-by" != _|_ {"replaced-by": g."replaced-by"}
+		}]
+	}
+
+	if input.exemptions != _|_ {exemptions: input.exemptions}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Policy migration
+//
+// Bumps gemara-version; converts imports.policies ids to mappings,
+// converts risks keyed by reference-id/risk-id into entries with an id
+// and a risk mapping, and fills the id and required flag of accepted
+// evaluation and enforcement methods.
+
+package migrate
+
+import gemara "github.com/gemaraproj/gemara@v1"
+
+input: {...}
+target_gemara_version: string
+
+// _riskMapping converts a v0 risk entry, which references the risk
+// directly, into its v1 mapping.
+_riskMapping: {
+	in: {...}
+	out: {
+		if in.risk != _|_ {in.risk}
+		if in.risk == _|_ {
+			"reference-id": in."reference-id"
+			"entry-id":     in."risk-id"
+		}
+	}
+}
+
+// _method fills the fields that v1 requires on accepted methods.
+_method: {
+	in: {...}
+	out: {
+		for k, v in in if k != "id" && k != "required" {(k): v}
+		if in.id != _|_ {id: in.id}
+		if in.id == _|_ {id: "REPLACE ME"}
+		if in.required != _|_ {required: in.required}
+		if in.required == _|_ {required: false}
+	}
+}
+
+output: gemara.#Policy & {
+	metadata: {
+		if input.metadata.id != _|_ {id: input.metadata.id}
+		if input.metadata.id == _|_ {id: "REPLACE ME"}
+		type: "Policy"
+		"gemara-version": target_gemara_version
+		if input.metadata.description != _|_ {description: input.metadata.description}
+		if input.metadata.description == _|_ {description: "REPLACE ME"}
+		if input.metadata.version != _|_ {version: input.metadata.version}
+		if input.metadata.author != _|_ {author: input.metadata.author}
+		if input.metadata.author == _|_ {
+			author: {
+				id:   "REPLACE ME"
+				name: "REPLACE ME"
+				type: "Human"
+			}
+		}
+		if input.metadata."mapping-references" != _|_ {"mapping-references": input.metadata."mapping-references"}
+		if input.metadata."applicability-categories" != _|_ {"applicability-groups": input.metadata."applicability-categories"}
+		if input.metadata."applicability-groups" != _|_ {"applicability-groups": input.metadata."applicability-groups"}
+	}
+
+	if input.title != _|_ {title: input.title}
+	if input.title == _|_ {title: "REPLACE ME"}
+
+	if input.contacts != _|_ {contacts: input.contacts}
+	if input.contacts == _|_ {
+		contacts: {
+			responsible: [{name: "REPLACE ME"}]
+			accountable: [{name: "REPLACE ME"}]
+		}
+	}
+
+	if input.scope != _|_ {scope: input.scope}
+	if input.scope == _|_ {scope: in: {}}
+
+	imports: {
+		if input.imports != _|_ {
+			if input.imports.policies != _|_ {
+				policies: [for p in input.imports.policies {
+					if (p & string) != _|_ {"reference-id": p}
+					if (p & string) == _|_ {p}
+				}]
+			}
+			if input.imports.catalogs != _|_ {catalogs: input.imports.catalogs}
+			if input.imports.guidance != _|_ {guidance: input.imports.guidance}
+		}
+	}
+
+	if input."implementation-plan" != _|_ {"implementation-plan": input."implementation-plan"}
+
+	if input.risks != _|_ {
+		risks: {
+			if input.risks.mitigated != _|_ {
+				mitigated: [for r in input.risks.mitigated {
+					if r.id != _|_ {id: r.id}
+					if r.id == _|_ {id: r."risk-id"}
+					risk: (_riskMapping & {in: r}).out
+				}]
+			}
+			if input.risks.accepted != _|_ {
+				accepted: [for r in input.risks.accepted {
+					if r.id != _|_ {id: r.id}
+					if r.id == _|_ {id: r."risk-id"}
+					if r."target-id" != _|_ {"target-id": r."target-id"}
+					risk: (_riskMapping & {in: r}).out
+					if r.scope != _|_ {scope: r.scope}
+					if r.justification != _|_ {justification: r.justification}
+				}]
+			}
+		}
+	}
+
+	if input.adherence != _|_ {
+		adherence: {
+			if input.adherence."evaluation-methods" != _|_ {
+				"evaluation-methods": [for m in input.adherence."evaluation-methods" {(_method & {in: m}).out}]
+			}
+			if input.adherence."assessment-plans" != _|_ {
+				"assessment-plans": [for ap in input.adherence."assessment-plans" {
+					for k, v in ap if k != "evaluation-methods" {(k): v}
+					if ap."evaluation-methods" != _|_ {
+						"evaluation-methods": [for m in ap."evaluation-methods" {(_method & {in: m}).out}]
+					}
+				}]
+			}
+			if input.adherence."enforcement-methods" != _|_ {
+				"enforcement-methods": [for m in input.adherence."enforcement-methods" {(_method & {in: m}).out}]
+			}
+			if input.adherence."non-compliance" != _|_ {"non-compliance": input.adherence."non-compliance"}
+		}
+	}
+	if input.adherence == _|_ {adherence: {}}
+}
@@ -6,22 +6,47 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"slices"
 	"time"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/mod/modconfig"
 	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
 	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// GemaraModulePath is the CUE module that publishes the Gemara schemas.
+const GemaraModulePath = "github.com/gemaraproj/gemara"
+
 const (
 	defaultSchemaVersion = "latest"
-	gemaraModulePath     = "github.com/gemaraproj/gemara"
-	gemaraModuleBase     = gemaraModulePath + "@"
+	gemaraModuleBase     = GemaraModulePath + "@"
 	lexiconBaseURL       = "https://raw.githubusercontent.com/gemaraproj/gemara/"
 	lexiconPathSuffix    = "/docs/lexicon.yaml"
 )
 
+// Token scopes that authorize tool calls on authenticated (shared) instances.
+const (
+	// ScopeAdvisory grants access to AdvisoryMode tools only.
+	ScopeAdvisory = "advisory"
+	// ScopeArtifact grants access to every tool, including ArtifactMode tools.
+	ScopeArtifact = "artifact"
+)
+
+// ToolScopes returns the token scopes, any one of which authorizes a call to
+// the named tool. AdvisoryMode tools accept either scope; every other tool
+// requires ScopeArtifact.
+func ToolScopes(tool string) []string {
+	switch tool {
+	case MetadataValidateGemaraArtifact.Name, MetadataValidateGemaraArtifacts.Name, MetadataCheckGemaraReferences.Name:
+		return []string{ScopeAdvisory, ScopeArtifact}
+	default:
+		return []string{ScopeArtifact}
+	}
+}
+
 // Mode represents the operational mode of the MCP server.
 type Mode interface {
 	// Name returns the string representation of the mode.
@@ -30,35 +55,163 @@ type Mode interface {
 	Description() string
 	// Register adds mode-related tools and resources to the mcp server
 	Register(*mcp.Server)
+	// Complete answers completion/complete requests for the arguments of
+	// the mode's prompts and resource templates.
+	Complete(context.Context, *mcp.CompleteRequest) (*mcp.CompleteResult, error)
+}
+
+// ModeOption configures optional behavior of AdvisoryMode and ArtifactMode.
+type ModeOption func(*modeConfig)
+
+type modeConfig struct {
+	schemaRegistry       modconfig.Registry
+	cacheDir             string
+	staleWhileRevalidate bool
+	cache                CacheConfig
+	migrationRulesDir    string
+	workspace            string
+}
+
+// CacheConfig tunes the lexicon, schema and version caches. Zero TTLs fall
+// back to the cache TTL passed to the mode constructor.
+type CacheConfig struct {
+	SchemaTTL  time.Duration
+	LexiconTTL time.Duration
+	VersionTTL time.Duration
+	// MaxEntries bounds each cache; the least recently used entries are
+	// evicted beyond it. Zero means unbounded.
+	MaxEntries int
+}
+
+// ttlOr returns ttl, or fallback when ttl is zero.
+func ttlOr(ttl, fallback time.Duration) time.Duration {
+	if ttl == 0 {
+		return fallback
+	}
+	return ttl
+}
+
+// WithCacheConfig sets per-cache TTLs and size bounds.
+func WithCacheConfig(cfg CacheConfig) ModeOption {
+	return func(c *modeConfig) {
+		c.cache = cfg
+	}
+}
+
+// WithSchemaRegistry loads schemas and resolves schema versions from reg,
+// such as an offline schema.Bundle, instead of the remote CUE registry.
+func WithSchemaRegistry(reg modconfig.Registry) ModeOption {
+	return func(c *modeConfig) {
+		c.schemaRegistry = reg
+	}
+}
+
+// WithCacheDir persists the lexicon, schema and version caches under dir so
+// they survive restarts and can be served stale when the network is down.
+func WithCacheDir(dir string) ModeOption {
+	return func(c *modeConfig) {
+		c.cacheDir = dir
+	}
+}
+
+// WithStaleWhileRevalidate serves expired lexicon, schema and version cache
+// entries immediately and refreshes them in the background.
+func WithStaleWhileRevalidate() ModeOption {
+	return func(c *modeConfig) {
+		c.staleWhileRevalidate = true
+	}
+}
+
+// WithMigrationRulesDir applies the CUE rule packs in dir after the built-in
+// migrations of migrate_gemara_artifact. It only affects ArtifactMode.
+func WithMigrationRulesDir(dir string) ModeOption {
+	return func(c *modeConfig) {
+		c.migrationRulesDir = dir
+	}
+}
+
+// WithWorkspace completes the id_prefix argument of prompts from the
+// metadata.id of the Gemara artifacts under dir. It only affects ArtifactMode.
+func WithWorkspace(dir string) ModeOption {
+	return func(c *modeConfig) {
+		c.workspace = dir
+	}
 }
 
 // AdvisoryMode defines tools and resources for operating in a read-only query mode
 type AdvisoryMode struct {
-	schemaCache       *fetcher.Cache[cue.Value]
-	lexiconCache      *fetcher.Cache[[]byte]
-	versionResolver   *fetcher.CachedFetcher[string]
-	lexiconURLBuilder *fetcher.URLBuilder
+	schemaCache          *fetcher.Cache[cue.Value]
+	schemaRegistry       modconfig.Registry
+	lexiconCache         *fetcher.Cache[[]byte]
+	versionResolver      *fetcher.CachedFetcher[string]
+	versionList          *fetcher.CachedFetcher[[]string]
+	lexiconURLBuilder    *fetcher.URLBuilder
+	staleWhileRevalidate bool
 }
 
 // NewAdvisoryMode creates a new AdvisoryMode with the provided cache TTL.
-func NewAdvisoryMode(cacheTTL time.Duration) (*AdvisoryMode, error) {
+func NewAdvisoryMode(cacheTTL time.Duration, opts ...ModeOption) (*AdvisoryMode, error) {
+	var cfg modeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	lexiconBuilder, err := fetcher.NewURLBuilder(lexiconBaseURL, lexiconPathSuffix)
 	if err != nil {
 		return nil, fmt.Errorf("creating lexicon URL builder: %w", err)
 	}
-	versionCache := fetcher.NewCache[string](cacheTTL)
-	resolver := schema.NewCUEVersionResolver(gemaraModulePath)
-	versionResolver := fetcher.NewCachedFetcher[string](resolver, versionCache, gemaraModulePath)
+	maxEntries := fetcher.WithMaxEntries(cfg.cache.MaxEntries)
+	versionCache, err := newCache(ttlOr(cfg.cache.VersionTTL, cacheTTL), cfg.cacheDir, "versions", fetcher.StringCodec, maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	versionListCache, err := newCache(ttlOr(cfg.cache.VersionTTL, cacheTTL), cfg.cacheDir, "version-lists", fetcher.JSONCodec[[]string](), maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	schemaCache, err := newCache(ttlOr(cfg.cache.SchemaTTL, cacheTTL), cfg.cacheDir, "schemas", fetcher.Codec[cue.Value]{
+		Marshal:   schema.MarshalValue,
+		Unmarshal: schema.UnmarshalValue,
+	}, maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	lexiconCache, err := newCache(ttlOr(cfg.cache.LexiconTTL, cacheTTL), cfg.cacheDir, "lexicon", fetcher.BytesCodec, maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	resolver := schema.NewCUEVersionResolver(GemaraModulePath)
+	resolver.Registry = cfg.schemaRegistry
+	versionResolver := fetcher.NewCachedFetcher[string](resolver, versionCache, GemaraModulePath)
+	versionResolver.StaleWhileRevalidate = cfg.staleWhileRevalidate
+	versionList := fetcher.NewCachedFetcher[[]string](schema.CUEVersionList{Resolver: resolver}, versionListCache, GemaraModulePath)
+	versionList.StaleWhileRevalidate = cfg.staleWhileRevalidate
 
-	slog.Info("mode initialized", "mode", "advisory")
+	slog.Info("mode initialized", "mode", "advisory", "cache_dir", cfg.cacheDir)
 	return &AdvisoryMode{
-		schemaCache:       fetcher.NewCache[cue.Value](cacheTTL),
-		lexiconCache:      fetcher.NewCache[[]byte](cacheTTL),
-		versionResolver:   versionResolver,
-		lexiconURLBuilder: lexiconBuilder,
+		schemaCache:          schemaCache,
+		schemaRegistry:       cfg.schemaRegistry,
+		lexiconCache:         lexiconCache,
+		versionResolver:      versionResolver,
+		versionList:          versionList,
+		lexiconURLBuilder:    lexiconBuilder,
+		staleWhileRevalidate: cfg.staleWhileRevalidate,
 	}, nil
 }
 
+// newCache returns an in-memory cache, or a disk-backed cache in a
+// subdirectory of cacheDir when one is configured.
+func newCache[T any](ttl time.Duration, cacheDir, name string, codec fetcher.Codec[T], opts ...fetcher.CacheOption) (*fetcher.Cache[T], error) {
+	if cacheDir == "" {
+		return fetcher.NewCache[T](ttl, opts...), nil
+	}
+	c, err := fetcher.NewDiskCache(ttl, filepath.Join(cacheDir, name), codec, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s cache: %w", name, err)
+	}
+	return c, nil
+}
+
 func (a *AdvisoryMode) Name() string {
 	return "advisory"
 }
@@ -66,13 +219,15 @@ func (a *AdvisoryMode) Name() string {
 func (a *AdvisoryMode) Description() string {
 	return `Gemara advisory mode. Analyze and validate existing security artifacts.
 
-Tools: validate_gemara_artifact. Resources: gemara://lexicon, gemara://schema/definitions. Resource templates: gemara://schema/definitions{?version}.
+Tools: validate_gemara_artifact, validate_gemara_artifacts, check_gemara_references. Resources: gemara://lexicon, gemara://schema/definitions. Resource templates: gemara://schema/definitions{?version,definition}.
 
 For artifact creation, suggest switching to artifact mode.`
 }
 
 func (a *AdvisoryMode) Register(server *mcp.Server) {
 	mcp.AddTool(server, MetadataValidateGemaraArtifact, a.validateGemaraArtifact)
+	mcp.AddTool(server, MetadataValidateGemaraArtifacts, a.validateGemaraArtifacts)
+	mcp.AddTool(server, MetadataCheckGemaraReferences, CheckGemaraReferences)
 	server.AddResource(ResourceLexicon, a.handleLexiconResource)
 	server.AddResource(ResourceSchemaDocs, a.handleSchemaDocsResource)
 	server.AddResourceTemplate(ResourceSchemaDocsTemplate, a.handleSchemaDocsTemplateResource)
@@ -81,16 +236,38 @@ func (a *AdvisoryMode) Register(server *mcp.Server) {
 // ArtifactMode extends AdvisoryMode with guided wizards for creating Gemara artifacts.
 type ArtifactMode struct {
 	*AdvisoryMode
+	rulePacks   []RulePack
+	workspace   string
+	prefixCache *fetcher.Cache[[]string]
 }
 
 // NewArtifactMode creates a new ArtifactMode with all AdvisoryMode capabilities plus artifact prompts.
-func NewArtifactMode(cacheTTL time.Duration) (*ArtifactMode, error) {
-	advisory, err := NewAdvisoryMode(cacheTTL)
+func NewArtifactMode(cacheTTL time.Duration, opts ...ModeOption) (*ArtifactMode, error) {
+	var cfg modeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var packs []RulePack
+	if cfg.migrationRulesDir != "" {
+		var err error
+		packs, err = LoadRulePacks(cfg.migrationRulesDir)
+		if err != nil {
+			return nil, fmt.Errorf("loading migration rule packs: %w", err)
+		}
+	}
+
+	advisory, err := NewAdvisoryMode(cacheTTL, opts...)
 	if err != nil {
 		return nil, err
 	}
-	slog.Info("mode initialized", "mode", "artifact")
-	return &ArtifactMode{AdvisoryMode: advisory}, nil
+	slog.Info("mode initialized", "mode", "artifact", "rule_packs", len(packs))
+	return &ArtifactMode{
+		AdvisoryMode: advisory,
+		rulePacks:    packs,
+		workspace:    cfg.workspace,
+		prefixCache:  fetcher.NewCache[[]string](workspacePrefixTTL),
+	}, nil
 }
 
 func (a *ArtifactMode) Name() string {
@@ -100,7 +277,7 @@ func (a *ArtifactMode) Name() string {
 func (a *ArtifactMode) Description() string {
 	return `Gemara artifact mode. Create, iterate on, and validate security artifacts.
 
-Tools: validate_gemara_artifact, migrate_gemara_artifact. Resources: gemara://lexicon, gemara://schema/definitions. Resource templates: gemara://schema/definitions{?version}. Prompts: threat_assessment, control_catalog, migration.
+Tools: validate_gemara_artifact, validate_gemara_artifacts, check_gemara_references, migrate_gemara_artifact. Resources: gemara://lexicon, gemara://schema/definitions. Resource templates: gemara://schema/definitions{?version,definition}. Prompts: capability_catalog, threat_assessment, control_catalog, guidance_catalog, policy, evaluation, migration.
 
 Offer wizard prompts for new artifacts. Validate frequently during iteration.`
 }
@@ -112,13 +289,19 @@ func (a *ArtifactMode) Register(server *mcp.Server) {
 
 	fetchLexicon := a.lexiconFetcher()
 	fetchSchemaDocs := a.schemaDocsFetcher()
+	server.AddPrompt(PromptCapabilityCatalog, NewCapabilityCatalogHandler(fetchLexicon, fetchSchemaDocs))
 	server.AddPrompt(PromptThreatAssessment, NewThreatAssessmentHandler(fetchLexicon, fetchSchemaDocs))
 	server.AddPrompt(PromptControlCatalog, NewControlCatalogHandler(fetchLexicon, fetchSchemaDocs))
+	server.AddPrompt(PromptGuidanceCatalog, NewGuidanceCatalogHandler(fetchLexicon, fetchSchemaDocs))
+	server.AddPrompt(PromptPolicy, NewPolicyHandler(fetchLexicon, fetchSchemaDocs))
+	server.AddPrompt(PromptEvaluation, NewEvaluationHandler(fetchLexicon, fetchSchemaDocs))
 	server.AddPrompt(PromptMigration, NewMigrationHandler(fetchLexicon, fetchSchemaDocs))
 }
 
+// migrateGemaraArtifact wraps MigrateGemaraArtifact with schema cache access
+// and the configured rule packs.
 func (a *ArtifactMode) migrateGemaraArtifact(ctx context.Context, req *mcp.CallToolRequest, input InputMigrateGemaraArtifact) (*mcp.CallToolResult, OutputMigrateGemaraArtifact, error) {
-	return MigrateGemaraArtifact(ctx, req, input)
+	return MigrateGemaraArtifact(ctx, req, input, a.Schemas(), a.rulePacks)
 }
 
 // lexiconFetcher returns a LexiconFetcher that always succeeds because
@@ -132,11 +315,7 @@ func (a *AdvisoryMode) lexiconFetcher() LexiconFetcher {
 
 func (a *AdvisoryMode) schemaDocsFetcher() SchemaDocsFetcher {
 	return func(ctx context.Context) (string, error) {
-		modulePath := gemaraModuleBase + defaultSchemaVersion
-		f := schema.NewCUERegistryFetcher(modulePath)
-		cf := fetcher.NewCachedFetcher[cue.Value](f, a.schemaCache, modulePath)
-
-		val, _, err := cf.Fetch(ctx, false)
+		val, _, err := a.schemaFetcher(defaultSchemaVersion).Fetch(ctx, false)
 		if err != nil {
 			return "", fmt.Errorf("failed to fetch schema: %w", err)
 		}
@@ -146,12 +325,40 @@ func (a *AdvisoryMode) schemaDocsFetcher() SchemaDocsFetcher {
 
 // validateGemaraArtifact wraps ValidateGemaraArtifact with schema cache access.
 func (a *AdvisoryMode) validateGemaraArtifact(ctx context.Context, req *mcp.CallToolRequest, input InputValidateGemaraArtifact) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
+	return ValidateGemaraArtifact(ctx, req, input, a.Schemas())
+}
+
+// validateGemaraArtifacts wraps ValidateGemaraArtifacts with schema cache access.
+func (a *AdvisoryMode) validateGemaraArtifacts(ctx context.Context, req *mcp.CallToolRequest, input InputValidateGemaraArtifacts) (*mcp.CallToolResult, OutputValidateGemaraArtifacts, error) {
 	version := input.Version
 	if version == "" {
 		version = defaultSchemaVersion
 	}
+	return ValidateGemaraArtifacts(ctx, req, input, a.schemaFetcher(version))
+}
+
+// Schemas returns the mode's cached schema source, for running tools such as
+// ValidateGemaraArtifact outside an MCP server.
+func (a *AdvisoryMode) Schemas() SchemaSource {
+	return SchemaSource{Schema: a.schemaFetcher, Versions: a.schemaVersions, Registry: a.schemaRegistry}
+}
+
+// schemaVersions returns the published schema module versions, oldest first.
+// The list is a copy, so callers may reorder it.
+func (a *AdvisoryMode) schemaVersions(ctx context.Context) ([]string, error) {
+	list, _, err := a.versionList.Fetch(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(list), nil
+}
+
+// schemaFetcher returns a cached fetcher for the Gemara schema at the given version.
+func (a *AdvisoryMode) schemaFetcher(version string) *fetcher.CachedFetcher[cue.Value] {
 	modulePath := gemaraModuleBase + version
 	f := schema.NewCUERegistryFetcher(modulePath)
+	f.Registry = a.schemaRegistry
 	cf := fetcher.NewCachedFetcher[cue.Value](f, a.schemaCache, modulePath)
-	return ValidateGemaraArtifact(ctx, req, input, cf)
+	cf.StaleWhileRevalidate = a.staleWhileRevalidate
+	return cf
 }
@@ -4,6 +4,8 @@ package server
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -14,6 +16,8 @@ import (
 
 var advisoryToolNames = []string{
 	"validate_gemara_artifact",
+	"validate_gemara_artifacts",
+	"check_gemara_references",
 }
 
 var artifactToolNames = []string{
@@ -30,8 +34,12 @@ var advisoryResourceTemplateURIs = []string{
 }
 
 var artifactPromptNames = []string{
+	"capability_catalog",
 	"threat_assessment",
 	"control_catalog",
+	"guidance_catalog",
+	"policy",
+	"evaluation",
 	"migration",
 }
 
@@ -178,7 +186,7 @@ func TestAdvisoryModeMetadata(t *testing.T) {
 	assert.Equal(t, "advisory", mode.Name())
 	assert.Contains(t, mode.Description(), "advisory mode")
 	assert.Contains(t, mode.Description(), "gemara://lexicon")
-	assert.Contains(t, mode.Description(), "gemara://schema/definitions{?version}")
+	assert.Contains(t, mode.Description(), "gemara://schema/definitions{?version,definition}")
 	assert.NotContains(t, mode.Description(), "- term:", "lexicon must not be embedded in description")
 }
 
@@ -191,10 +199,35 @@ func TestArtifactModeMetadata(t *testing.T) {
 	assert.Contains(t, mode.Description(), "migrate_gemara_artifact")
 	assert.Contains(t, mode.Description(), "migration")
 	assert.Contains(t, mode.Description(), "gemara://lexicon")
-	assert.Contains(t, mode.Description(), "gemara://schema/definitions{?version}")
+	assert.Contains(t, mode.Description(), "gemara://schema/definitions{?version,definition}")
 	assert.NotContains(t, mode.Description(), "- term:", "lexicon must not be embedded in description")
 }
 
+func TestArtifactModeMigrationRulesDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ids.cue"), []byte("package migrate\n"), 0o600))
+
+	mode, err := NewArtifactMode(1*time.Hour, WithMigrationRulesDir(dir))
+	require.NoError(t, err)
+	require.Len(t, mode.rulePacks, 1)
+	assert.Equal(t, "ids", mode.rulePacks[0].Name)
+
+	_, err = NewArtifactMode(1*time.Hour, WithMigrationRulesDir(filepath.Join(dir, "missing")))
+	require.ErrorContains(t, err, "loading migration rule packs")
+}
+
+func TestToolScopes(t *testing.T) {
+	for _, name := range advisoryToolNames {
+		assert.ElementsMatch(t, []string{ScopeAdvisory, ScopeArtifact}, ToolScopes(name),
+			"advisory tool %s must accept advisory and artifact scopes", name)
+	}
+	for _, name := range artifactToolNames {
+		assert.Equal(t, []string{ScopeArtifact}, ToolScopes(name),
+			"artifact tool %s must require the artifact scope", name)
+	}
+	assert.Equal(t, []string{ScopeArtifact}, ToolScopes("unknown_tool"), "unknown tools fail closed")
+}
+
 func TestParseSchemaDocsVersion(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -6,14 +6,18 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 
+	"github.com/gemaraproj/go-gemara"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const (
 	maxPromptArgLen       = 200
+	maxCatalogArgLen      = 1 << 20
+	pastedCatalogURI      = "gemara://prompt/evaluation/catalog"
 	lexiconFallbackSource = "embedded"
 	lexiconWarning        = "Lexicon Notice: The Gemara lexicon was loaded from " +
 		"an embedded fallback because the remote source was unavailable. " +
@@ -49,14 +53,24 @@ func lexiconWarningMessage() *mcp.PromptMessage {
 }
 
 func validateComponent(value string) error {
+	return validateName("component", value)
+}
+
+func validateFramework(value string) error {
+	return validateName("framework", value)
+}
+
+// validateName checks a free-text name argument that is interpolated into
+// prompt templates.
+func validateName(arg, value string) error {
 	if value == "" {
-		return fmt.Errorf("component argument is required")
+		return fmt.Errorf("%s argument is required", arg)
 	}
 	if len(value) > maxPromptArgLen {
-		return fmt.Errorf("component argument exceeds maximum length of %d", maxPromptArgLen)
+		return fmt.Errorf("%s argument exceeds maximum length of %d", arg, maxPromptArgLen)
 	}
 	if !validComponentPattern.MatchString(value) {
-		return fmt.Errorf("component %q must match ^[a-zA-Z0-9][a-zA-Z0-9 ._-]*$ (letters, digits, spaces, dots, underscores, hyphens)", value)
+		return fmt.Errorf("%s %q must match ^[a-zA-Z0-9][a-zA-Z0-9 ._-]*$ (letters, digits, spaces, dots, underscores, hyphens)", arg, value)
 	}
 	return nil
 }
@@ -74,6 +88,49 @@ func validateIDPrefix(value string) error {
 	return nil
 }
 
+// controlCatalogMessage hands the catalog argument of the evaluation wizard
+// to the LLM: a resource link for an http(s) URL, or an embedded resource for
+// pasted YAML. It returns nil when no catalog was given.
+func controlCatalogMessage(catalog string) (*mcp.PromptMessage, error) {
+	catalog = strings.TrimSpace(catalog)
+	if catalog == "" {
+		return nil, nil
+	}
+	if len(catalog) > maxCatalogArgLen {
+		return nil, fmt.Errorf("catalog argument exceeds maximum length of %d", maxCatalogArgLen)
+	}
+
+	if u, err := url.Parse(catalog); err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" && !strings.ContainsAny(catalog, " \t\n") {
+		return &mcp.PromptMessage{
+			Role: "user",
+			Content: &mcp.ResourceLink{
+				URI:      catalog,
+				Name:     "control-catalog",
+				Title:    "Control Catalog",
+				MIMEType: "text/yaml",
+			},
+		}, nil
+	}
+
+	meta, err := readArtifactMetadata(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("catalog argument is neither an http(s) URL nor YAML content: %w", err)
+	}
+	if meta.Metadata.Type != "" && meta.Metadata.Type != gemara.ControlCatalogArtifact.String() {
+		return nil, fmt.Errorf("catalog argument is a %s, not a ControlCatalog", meta.Metadata.Type)
+	}
+	return &mcp.PromptMessage{
+		Role: "user",
+		Content: &mcp.EmbeddedResource{
+			Resource: &mcp.ResourceContents{
+				URI:      pastedCatalogURI,
+				MIMEType: "text/yaml",
+				Text:     catalog,
+			},
+		},
+	}, nil
+}
+
 func embeddedResourceMessages(lexicon string, schemaDocs string) []*mcp.PromptMessage {
 	return []*mcp.PromptMessage{
 		{
@@ -109,6 +166,15 @@ var (
 	//go:embed prompts/threat_assessment_user.md
 	threatAssessmentUserTemplate string
 
+	//go:embed prompts/capability_catalog_system.md
+	capabilityCatalogSystemTemplate string
+
+	//go:embed prompts/capability_catalog_assistant.md
+	capabilityCatalogAssistantTemplate string
+
+	//go:embed prompts/capability_catalog_user.md
+	capabilityCatalogUserTemplate string
+
 	//go:embed prompts/control_catalog_system.md
 	controlCatalogSystemTemplate string
 
@@ -118,6 +184,33 @@ var (
 	//go:embed prompts/control_catalog_user.md
 	controlCatalogUserTemplate string
 
+	//go:embed prompts/guidance_catalog_system.md
+	guidanceCatalogSystemTemplate string
+
+	//go:embed prompts/guidance_catalog_assistant.md
+	guidanceCatalogAssistantTemplate string
+
+	//go:embed prompts/guidance_catalog_user.md
+	guidanceCatalogUserTemplate string
+
+	//go:embed prompts/evaluation_system.md
+	evaluationSystemTemplate string
+
+	//go:embed prompts/evaluation_assistant.md
+	evaluationAssistantTemplate string
+
+	//go:embed prompts/evaluation_user.md
+	evaluationUserTemplate string
+
+	//go:embed prompts/policy_system.md
+	policySystemTemplate string
+
+	//go:embed prompts/policy_assistant.md
+	policyAssistantTemplate string
+
+	//go:embed prompts/policy_user.md
+	policyUserTemplate string
+
 	//go:embed prompts/migration_system.md
 	migrationSystemTemplate string
 
@@ -149,6 +242,27 @@ var PromptThreatAssessment = &mcp.Prompt{
 	},
 }
 
+// PromptCapabilityCatalog is the MCP prompt definition for the capability catalog wizard.
+var PromptCapabilityCatalog = &mcp.Prompt{
+	Name:        "capability_catalog",
+	Title:       "Capability Catalog Wizard",
+	Description: "Interactive wizard that guides you through creating a standalone Gemara-compatible Capability Catalog (Layer 2) for your project, e.g. from an architecture description.",
+	Arguments: []*mcp.PromptArgument{
+		{
+			Name:        "component",
+			Title:       "Component Name",
+			Description: "The name of the component or technology whose capabilities to catalog (e.g., 'container runtime', 'API gateway', 'object storage')",
+			Required:    true,
+		},
+		{
+			Name:        "id_prefix",
+			Title:       "ID Prefix",
+			Description: "Organization and project prefix for identifiers in ORG.PROJECT.COMPONENT format (e.g., 'ACME.PLAT.GW')",
+			Required:    true,
+		},
+	},
+}
+
 // PromptControlCatalog is the MCP prompt definition for the control catalog wizard.
 var PromptControlCatalog = &mcp.Prompt{
 	Name:        "control_catalog",
@@ -170,6 +284,74 @@ var PromptControlCatalog = &mcp.Prompt{
 	},
 }
 
+// PromptGuidanceCatalog is the MCP prompt definition for the guidance catalog wizard.
+var PromptGuidanceCatalog = &mcp.Prompt{
+	Name:        "guidance_catalog",
+	Title:       "Guidance Catalog Wizard",
+	Description: "Interactive wizard that guides you through turning a standard, regulation or framework into a Gemara-compatible Guidance Catalog (Layer 1).",
+	Arguments: []*mcp.PromptArgument{
+		{
+			Name:        "framework",
+			Title:       "Source Framework",
+			Description: "The standards document to capture as guidelines (e.g., 'NIST SP 800-53', 'OWASP ASVS', 'CIS Kubernetes Benchmark')",
+			Required:    true,
+		},
+		{
+			Name:        "id_prefix",
+			Title:       "ID Prefix",
+			Description: "Prefix for guideline identifiers, usually the framework's short name (e.g., 'NIST-800-53', 'ASVS')",
+			Required:    true,
+		},
+	},
+}
+
+// PromptPolicy is the MCP prompt definition for the policy wizard.
+var PromptPolicy = &mcp.Prompt{
+	Name:        "policy",
+	Title:       "Policy Wizard",
+	Description: "Interactive wizard that guides you through creating a Gemara-compatible Policy (Layer 3) that scopes control catalogs to your organization.",
+	Arguments: []*mcp.PromptArgument{
+		{
+			Name:        "component",
+			Title:       "Component Name",
+			Description: "The name of the system, platform or organization the policy governs (e.g., 'cloud storage', 'payments platform')",
+			Required:    true,
+		},
+		{
+			Name:        "id_prefix",
+			Title:       "ID Prefix",
+			Description: "Organization and project prefix for identifiers in ORG.PROJECT.COMPONENT format (e.g., 'ACME.PLAT.POL')",
+			Required:    true,
+		},
+	},
+}
+
+// PromptEvaluation is the MCP prompt definition for the evaluation wizard.
+var PromptEvaluation = &mcp.Prompt{
+	Name:        "evaluation",
+	Title:       "Evaluation Wizard",
+	Description: "Interactive wizard that guides you through planning an evaluation against a Control Catalog and recording the results and evidence as a Gemara-compatible Evaluation Log (Layer 5).",
+	Arguments: []*mcp.PromptArgument{
+		{
+			Name:        "component",
+			Title:       "Component Name",
+			Description: "The name of the component or system being evaluated (e.g., 'container runtime', 'API gateway', 'object storage')",
+			Required:    true,
+		},
+		{
+			Name:        "id_prefix",
+			Title:       "ID Prefix",
+			Description: "Organization and project prefix for identifiers in ORG.PROJECT.COMPONENT format (e.g., 'ACME.PLAT.GW')",
+			Required:    true,
+		},
+		{
+			Name:        "catalog",
+			Title:       "Control Catalog",
+			Description: "The Control Catalog to evaluate against, as an http(s) URL or pasted YAML content (default: the wizard asks for it)",
+		},
+	},
+}
+
 // PromptMigration is the MCP prompt definition for the schema migration wizard.
 var PromptMigration = &mcp.Prompt{
 	Name:        "migration",
@@ -185,6 +367,65 @@ var PromptMigration = &mcp.Prompt{
 	},
 }
 
+// NewCapabilityCatalogHandler returns a PromptHandler that embeds the lexicon and schema
+// docs as EmbeddedResource messages, guaranteeing the LLM receives both during the wizard.
+func NewCapabilityCatalogHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs SchemaDocsFetcher) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if req.Params == nil || req.Params.Arguments == nil {
+			return nil, fmt.Errorf("component argument is required")
+		}
+
+		component := req.Params.Arguments["component"]
+		idPrefix := req.Params.Arguments["id_prefix"]
+
+		if err := validateComponent(component); err != nil {
+			return nil, err
+		}
+		if err := validateIDPrefix(idPrefix); err != nil {
+			return nil, err
+		}
+
+		lexicon, lexiconSource, err := fetchLexicon(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching lexicon: %w", err)
+		}
+
+		schemaDocs, err := fetchSchemaDocs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching schema docs: %w", err)
+		}
+
+		pairs := append([]string{"${COMPONENT}", component, "${ID_PREFIX}", idPrefix}, templateReplacerPairs...)
+		r := strings.NewReplacer(pairs...)
+		resources := embeddedResourceMessages(lexicon, schemaDocs)
+
+		messages := make([]*mcp.PromptMessage, 0, len(resources)+4)
+		messages = append(messages, resources...)
+		if lexiconSource == lexiconFallbackSource {
+			messages = append(messages, lexiconWarningMessage())
+		}
+		messages = append(messages,
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(capabilityCatalogSystemTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: r.Replace(capabilityCatalogAssistantTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(capabilityCatalogUserTemplate)},
+			},
+		)
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Capability catalog wizard for %s (%s)", component, idPrefix),
+			Messages:    messages,
+		}, nil
+	}
+}
+
 // NewControlCatalogHandler returns a PromptHandler that embeds the lexicon and schema
 // docs as EmbeddedResource messages, guaranteeing the LLM receives both during the wizard.
 func NewControlCatalogHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs SchemaDocsFetcher) mcp.PromptHandler {
@@ -244,6 +485,191 @@ func NewControlCatalogHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs Schem
 	}
 }
 
+// NewGuidanceCatalogHandler returns a PromptHandler that embeds the lexicon and schema
+// docs as EmbeddedResource messages, guaranteeing the LLM receives both during the wizard.
+func NewGuidanceCatalogHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs SchemaDocsFetcher) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if req.Params == nil || req.Params.Arguments == nil {
+			return nil, fmt.Errorf("framework argument is required")
+		}
+
+		framework := req.Params.Arguments["framework"]
+		idPrefix := req.Params.Arguments["id_prefix"]
+
+		if err := validateFramework(framework); err != nil {
+			return nil, err
+		}
+		if err := validateIDPrefix(idPrefix); err != nil {
+			return nil, err
+		}
+
+		lexicon, lexiconSource, err := fetchLexicon(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching lexicon: %w", err)
+		}
+
+		schemaDocs, err := fetchSchemaDocs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching schema docs: %w", err)
+		}
+
+		pairs := append([]string{"${FRAMEWORK}", framework, "${ID_PREFIX}", idPrefix}, templateReplacerPairs...)
+		r := strings.NewReplacer(pairs...)
+		resources := embeddedResourceMessages(lexicon, schemaDocs)
+
+		messages := make([]*mcp.PromptMessage, 0, len(resources)+4)
+		messages = append(messages, resources...)
+		if lexiconSource == lexiconFallbackSource {
+			messages = append(messages, lexiconWarningMessage())
+		}
+		messages = append(messages,
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(guidanceCatalogSystemTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: r.Replace(guidanceCatalogAssistantTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(guidanceCatalogUserTemplate)},
+			},
+		)
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Guidance catalog wizard for %s (%s)", framework, idPrefix),
+			Messages:    messages,
+		}, nil
+	}
+}
+
+// NewEvaluationHandler returns a PromptHandler that embeds the lexicon and schema
+// docs as EmbeddedResource messages, guaranteeing the LLM receives both during the
+// wizard. A catalog argument is passed on after them, linked or embedded.
+func NewEvaluationHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs SchemaDocsFetcher) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if req.Params == nil || req.Params.Arguments == nil {
+			return nil, fmt.Errorf("component argument is required")
+		}
+
+		component := req.Params.Arguments["component"]
+		idPrefix := req.Params.Arguments["id_prefix"]
+
+		if err := validateComponent(component); err != nil {
+			return nil, err
+		}
+		if err := validateIDPrefix(idPrefix); err != nil {
+			return nil, err
+		}
+		catalog, err := controlCatalogMessage(req.Params.Arguments["catalog"])
+		if err != nil {
+			return nil, err
+		}
+
+		lexicon, lexiconSource, err := fetchLexicon(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching lexicon: %w", err)
+		}
+
+		schemaDocs, err := fetchSchemaDocs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching schema docs: %w", err)
+		}
+
+		pairs := append([]string{"${COMPONENT}", component, "${ID_PREFIX}", idPrefix}, templateReplacerPairs...)
+		r := strings.NewReplacer(pairs...)
+		resources := embeddedResourceMessages(lexicon, schemaDocs)
+
+		messages := make([]*mcp.PromptMessage, 0, len(resources)+5)
+		messages = append(messages, resources...)
+		if catalog != nil {
+			messages = append(messages, catalog)
+		}
+		if lexiconSource == lexiconFallbackSource {
+			messages = append(messages, lexiconWarningMessage())
+		}
+		messages = append(messages,
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(evaluationSystemTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: r.Replace(evaluationAssistantTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(evaluationUserTemplate)},
+			},
+		)
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Evaluation wizard for %s (%s)", component, idPrefix),
+			Messages:    messages,
+		}, nil
+	}
+}
+
+// NewPolicyHandler returns a PromptHandler that embeds the lexicon and schema
+// docs as EmbeddedResource messages, guaranteeing the LLM receives both during the wizard.
+func NewPolicyHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs SchemaDocsFetcher) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if req.Params == nil || req.Params.Arguments == nil {
+			return nil, fmt.Errorf("component argument is required")
+		}
+
+		component := req.Params.Arguments["component"]
+		idPrefix := req.Params.Arguments["id_prefix"]
+
+		if err := validateComponent(component); err != nil {
+			return nil, err
+		}
+		if err := validateIDPrefix(idPrefix); err != nil {
+			return nil, err
+		}
+
+		lexicon, lexiconSource, err := fetchLexicon(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching lexicon: %w", err)
+		}
+
+		schemaDocs, err := fetchSchemaDocs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching schema docs: %w", err)
+		}
+
+		pairs := append([]string{"${COMPONENT}", component, "${ID_PREFIX}", idPrefix}, templateReplacerPairs...)
+		r := strings.NewReplacer(pairs...)
+		resources := embeddedResourceMessages(lexicon, schemaDocs)
+
+		messages := make([]*mcp.PromptMessage, 0, len(resources)+4)
+		messages = append(messages, resources...)
+		if lexiconSource == lexiconFallbackSource {
+			messages = append(messages, lexiconWarningMessage())
+		}
+		messages = append(messages,
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(policySystemTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: r.Replace(policyAssistantTemplate)},
+			},
+			&mcp.PromptMessage{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: r.Replace(policyUserTemplate)},
+			},
+		)
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Policy wizard for %s (%s)", component, idPrefix),
+			Messages:    messages,
+		}, nil
+	}
+}
+
 // NewMigrationHandler returns a PromptHandler for the v0→v1 schema migration wizard.
 func NewMigrationHandler(fetchLexicon LexiconFetcher, fetchSchemaDocs SchemaDocsFetcher) mcp.PromptHandler {
 	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
@@ -5,6 +5,8 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -318,6 +320,124 @@ func TestNewThreatAssessmentHandlerSchemaFetchError(t *testing.T) {
 	assert.Contains(t, err.Error(), "network error")
 }
 
+func TestNewCapabilityCatalogHandler(t *testing.T) {
+	handler := NewCapabilityCatalogHandler(mockLexiconFetcher, mockSchemaFetcher)
+
+	tests := []struct {
+		name           string
+		arguments      map[string]string
+		wantErr        bool
+		errContains    string
+		validateResult func(t *testing.T, result *mcp.GetPromptResult)
+	}{
+		{
+			name: "successful prompt generation with embedded resources",
+			arguments: map[string]string{
+				"component": "API gateway",
+				"id_prefix": "ACME.PLAT.GW",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				assert.Contains(t, result.Description, "API gateway")
+				assert.Contains(t, result.Description, "ACME.PLAT.GW")
+				require.Len(t, result.Messages, 5, "2 embedded resources + 3 text messages")
+
+				assertEmbeddedResources(t, result.Messages)
+
+				instructionMsg := result.Messages[2]
+				assert.Equal(t, mcp.Role("user"), instructionMsg.Role)
+				text := instructionMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, text, "**Describe the Component**")
+				assert.Contains(t, text, "**Catalog Import**")
+				assert.Contains(t, text, "**Metadata**")
+				assert.Contains(t, text, "**Define Capability Groups**")
+				assert.Contains(t, text, "**Enumerate Capabilities**")
+				assert.Contains(t, text, "**Assemble and Validate**")
+				assert.Contains(t, text, "validate_gemara_artifact")
+				assert.Contains(t, text, "#CapabilityCatalog")
+				assert.Contains(t, text, "id: ACME.PLAT.GW")
+				assert.Contains(t, text, "ACME.PLAT.GW.CAP01")
+				assert.Contains(t, text, "API gateway Capability Catalog")
+				assert.NotContains(t, text, "${")
+
+				assistantMsg := result.Messages[3]
+				assert.Equal(t, mcp.Role("assistant"), assistantMsg.Role)
+				assistantText := assistantMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, assistantText, "API gateway")
+				assert.Contains(t, assistantText, "Step 1: Describe the Component")
+
+				userMsg := result.Messages[4]
+				assert.Equal(t, mcp.Role("user"), userMsg.Role)
+				userText := userMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, userText, "API gateway")
+				assert.Contains(t, userText, "ACME.PLAT.GW")
+			},
+		},
+		{
+			name: "missing component argument",
+			arguments: map[string]string{
+				"id_prefix": "ACME.PLAT.GW",
+			},
+			wantErr:     true,
+			errContains: "component",
+		},
+		{
+			name: "missing id_prefix argument",
+			arguments: map[string]string{
+				"component": "API gateway",
+			},
+			wantErr:     true,
+			errContains: "id_prefix",
+		},
+		{
+			name: "component with html tags rejected",
+			arguments: map[string]string{
+				"component": "<script>alert(1)</script>",
+				"id_prefix": "ACME.PLAT.GW",
+			},
+			wantErr:     true,
+			errContains: "must match",
+		},
+		{
+			name: "id_prefix with underscores rejected",
+			arguments: map[string]string{
+				"component": "API gateway",
+				"id_prefix": "ACME_PLAT_GW",
+			},
+			wantErr:     true,
+			errContains: "must match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			req := &mcp.GetPromptRequest{
+				Params: &mcp.GetPromptParams{
+					Name:      "capability_catalog",
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handler(ctx, req)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			if tt.validateResult != nil {
+				tt.validateResult(t, result)
+			}
+		})
+	}
+}
+
 func TestNewControlCatalogHandler(t *testing.T) {
 	handler := NewControlCatalogHandler(mockLexiconFetcher, mockSchemaFetcher)
 
@@ -588,6 +708,431 @@ func TestPromptControlCatalogMetadata(t *testing.T) {
 	assert.True(t, prefixArg.Required)
 }
 
+func TestNewGuidanceCatalogHandler(t *testing.T) {
+	handler := NewGuidanceCatalogHandler(mockLexiconFetcher, mockSchemaFetcher)
+
+	tests := []struct {
+		name           string
+		arguments      map[string]string
+		wantErr        bool
+		errContains    string
+		validateResult func(t *testing.T, result *mcp.GetPromptResult)
+	}{
+		{
+			name: "successful prompt generation with embedded resources",
+			arguments: map[string]string{
+				"framework": "NIST SP 800-53",
+				"id_prefix": "NIST-800-53",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				assert.Contains(t, result.Description, "NIST SP 800-53")
+				assert.Contains(t, result.Description, "NIST-800-53")
+				require.Len(t, result.Messages, 5, "2 embedded resources + 3 text messages")
+
+				assertEmbeddedResources(t, result.Messages)
+
+				instructionMsg := result.Messages[2]
+				assert.Equal(t, mcp.Role("user"), instructionMsg.Role)
+				text := instructionMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, text, "## Embedded Resources")
+				assert.Contains(t, text, "**Source Document**")
+				assert.Contains(t, text, "**Metadata**")
+				assert.Contains(t, text, "**Define Families**")
+				assert.Contains(t, text, "**Define Guidelines**")
+				assert.Contains(t, text, "**Cross-References**")
+				assert.Contains(t, text, "**Assemble and Validate**")
+				assert.Contains(t, text, "validate_gemara_artifact")
+				assert.Contains(t, text, "#GuidanceCatalog")
+
+				assistantMsg := result.Messages[3]
+				assert.Equal(t, mcp.Role("assistant"), assistantMsg.Role)
+				assistantText := assistantMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, assistantText, "NIST SP 800-53")
+				assert.Contains(t, assistantText, "Step 1: Source Document")
+
+				userMsg := result.Messages[4]
+				assert.Equal(t, mcp.Role("user"), userMsg.Role)
+				userText := userMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, userText, "NIST SP 800-53")
+				assert.Contains(t, userText, "NIST-800-53")
+			},
+		},
+		{
+			name: "framework and id prefix embedded in YAML template",
+			arguments: map[string]string{
+				"framework": "OWASP ASVS",
+				"id_prefix": "ASVS",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				text := result.Messages[2].Content.(*mcp.TextContent).Text
+				assert.Contains(t, text, "id: ASVS")
+				assert.Contains(t, text, "type: GuidanceCatalog")
+				assert.Contains(t, text, "title: OWASP ASVS")
+				assert.Contains(t, text, "ASVS.G##")
+				assert.NotContains(t, text, "${")
+			},
+		},
+		{
+			name: "missing framework argument",
+			arguments: map[string]string{
+				"id_prefix": "ASVS",
+			},
+			wantErr:     true,
+			errContains: "framework argument is required",
+		},
+		{
+			name: "missing id_prefix argument",
+			arguments: map[string]string{
+				"framework": "OWASP ASVS",
+			},
+			wantErr:     true,
+			errContains: "id_prefix",
+		},
+		{
+			name: "framework with markdown link injection rejected",
+			arguments: map[string]string{
+				"framework": "click](http://evil.com)",
+				"id_prefix": "ASVS",
+			},
+			wantErr:     true,
+			errContains: "framework \"click](http://evil.com)\" must match",
+		},
+		{
+			name: "framework exceeding maximum length rejected",
+			arguments: map[string]string{
+				"framework": strings.Repeat("a", maxPromptArgLen+1),
+				"id_prefix": "ASVS",
+			},
+			wantErr:     true,
+			errContains: "framework argument exceeds maximum length",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			req := &mcp.GetPromptRequest{
+				Params: &mcp.GetPromptParams{
+					Name:      "guidance_catalog",
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handler(ctx, req)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			if tt.validateResult != nil {
+				tt.validateResult(t, result)
+			}
+		})
+	}
+}
+
+func TestNewPolicyHandler(t *testing.T) {
+	handler := NewPolicyHandler(mockLexiconFetcher, mockSchemaFetcher)
+
+	tests := []struct {
+		name           string
+		arguments      map[string]string
+		wantErr        bool
+		errContains    string
+		validateResult func(t *testing.T, result *mcp.GetPromptResult)
+	}{
+		{
+			name: "successful prompt generation with embedded resources",
+			arguments: map[string]string{
+				"component": "cloud storage",
+				"id_prefix": "ACME.PLAT.POL",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				assert.Contains(t, result.Description, "cloud storage")
+				assert.Contains(t, result.Description, "ACME.PLAT.POL")
+				require.Len(t, result.Messages, 5, "2 embedded resources + 3 text messages")
+
+				assertEmbeddedResources(t, result.Messages)
+
+				instructionMsg := result.Messages[2]
+				assert.Equal(t, mcp.Role("user"), instructionMsg.Role)
+				text := instructionMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, text, "## Embedded Resources")
+				assert.Contains(t, text, "## Available Tools")
+				assert.Contains(t, text, "**Catalog Imports**")
+				assert.Contains(t, text, "**Metadata and Contacts**")
+				assert.Contains(t, text, "**Scope**")
+				assert.Contains(t, text, "**Control Selection**")
+				assert.Contains(t, text, "**Assessment Plans**")
+				assert.Contains(t, text, "**Enforcement and Non-Compliance**")
+				assert.Contains(t, text, "**Assemble and Validate**")
+				assert.Contains(t, text, "**Next Steps**")
+				assert.Contains(t, text, "validate_gemara_artifact")
+				assert.Contains(t, text, "check_gemara_references")
+				assert.Contains(t, text, "#Policy")
+
+				assistantMsg := result.Messages[3]
+				assert.Equal(t, mcp.Role("assistant"), assistantMsg.Role)
+				assistantText := assistantMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, assistantText, "cloud storage")
+				assert.Contains(t, assistantText, "Step 1: Catalog Imports")
+				assert.Contains(t, assistantText, "reply \"yes\"")
+
+				userMsg := result.Messages[4]
+				assert.Equal(t, mcp.Role("user"), userMsg.Role)
+				userText := userMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, userText, "cloud storage")
+				assert.Contains(t, userText, "ACME.PLAT.POL")
+			},
+		},
+		{
+			name: "id prefix, component and version embedded in YAML template",
+			arguments: map[string]string{
+				"component": "payments platform",
+				"id_prefix": "SEC.PAY.POL",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				text := result.Messages[2].Content.(*mcp.TextContent).Text
+				assert.Contains(t, text, "id: SEC.PAY.POL")
+				assert.Contains(t, text, "type: Policy")
+				assert.Contains(t, text, "gemara-version: \""+DefaultGemaraVersion+"\"")
+				assert.Contains(t, text, "payments platform Security Policy")
+				assert.Contains(t, text, "SEC.PAY.POL.AP##")
+				assert.NotContains(t, text, "${")
+			},
+		},
+		{
+			name: "missing id_prefix argument",
+			arguments: map[string]string{
+				"component": "cloud storage",
+			},
+			wantErr:     true,
+			errContains: "id_prefix",
+		},
+		{
+			name:        "both arguments missing",
+			arguments:   map[string]string{},
+			wantErr:     true,
+			errContains: "component",
+		},
+		{
+			name: "component with template interpolation rejected",
+			arguments: map[string]string{
+				"component": "${EVIL_VAR}",
+				"id_prefix": "ACME.PLAT.POL",
+			},
+			wantErr:     true,
+			errContains: "must match",
+		},
+		{
+			name: "id_prefix with lowercase letters rejected",
+			arguments: map[string]string{
+				"component": "cloud storage",
+				"id_prefix": "acme.plat.pol",
+			},
+			wantErr:     true,
+			errContains: "must match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			req := &mcp.GetPromptRequest{
+				Params: &mcp.GetPromptParams{
+					Name:      "policy",
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handler(ctx, req)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			if tt.validateResult != nil {
+				tt.validateResult(t, result)
+			}
+		})
+	}
+}
+
+func TestNewEvaluationHandler(t *testing.T) {
+	handler := NewEvaluationHandler(mockLexiconFetcher, mockSchemaFetcher)
+
+	const pastedCatalog = "metadata:\n  id: ACME.CC\n  type: ControlCatalog\ntitle: ACME Controls\n"
+
+	tests := []struct {
+		name           string
+		arguments      map[string]string
+		wantErr        bool
+		errContains    string
+		validateResult func(t *testing.T, result *mcp.GetPromptResult)
+	}{
+		{
+			name: "successful prompt generation without a catalog",
+			arguments: map[string]string{
+				"component": "object storage",
+				"id_prefix": "ACME.PLAT.OS",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				assert.Contains(t, result.Description, "object storage")
+				assert.Contains(t, result.Description, "ACME.PLAT.OS")
+				require.Len(t, result.Messages, 5, "2 embedded resources + 3 text messages")
+
+				assertEmbeddedResources(t, result.Messages)
+
+				instructionMsg := result.Messages[2]
+				assert.Equal(t, mcp.Role("user"), instructionMsg.Role)
+				text := instructionMsg.Content.(*mcp.TextContent).Text
+				assert.Contains(t, text, "**Control Catalog**")
+				assert.Contains(t, text, "**Target and Scope**")
+				assert.Contains(t, text, "**Evaluation Plan**")
+				assert.Contains(t, text, "**Conduct Assessments**")
+				assert.Contains(t, text, "**Summarize Results**")
+				assert.Contains(t, text, "**Assemble and Validate**")
+				assert.Contains(t, text, "validate_gemara_artifact")
+				assert.Contains(t, text, "#EvaluationLog")
+				assert.Contains(t, text, "id: ACME.PLAT.OS")
+				assert.NotContains(t, text, "${")
+
+				assistantMsg := result.Messages[3]
+				assert.Equal(t, mcp.Role("assistant"), assistantMsg.Role)
+				assert.Contains(t, assistantMsg.Content.(*mcp.TextContent).Text, "Step 1: Control Catalog")
+
+				userMsg := result.Messages[4]
+				assert.Equal(t, mcp.Role("user"), userMsg.Role)
+				assert.Contains(t, userMsg.Content.(*mcp.TextContent).Text, "object storage")
+			},
+		},
+		{
+			name: "catalog URL passed as a resource link",
+			arguments: map[string]string{
+				"component": "object storage",
+				"id_prefix": "ACME.PLAT.OS",
+				"catalog":   "https://example.com/catalogs/controls.yaml",
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				require.Len(t, result.Messages, 6, "2 embedded resources + catalog + 3 text messages")
+				link, ok := result.Messages[2].Content.(*mcp.ResourceLink)
+				require.True(t, ok, "catalog URL should be a resource link")
+				assert.Equal(t, "https://example.com/catalogs/controls.yaml", link.URI)
+				assert.Equal(t, "text/yaml", link.MIMEType)
+			},
+		},
+		{
+			name: "pasted catalog passed as an embedded resource",
+			arguments: map[string]string{
+				"component": "object storage",
+				"id_prefix": "ACME.PLAT.OS",
+				"catalog":   pastedCatalog,
+			},
+			wantErr: false,
+			validateResult: func(t *testing.T, result *mcp.GetPromptResult) {
+				require.Len(t, result.Messages, 6, "2 embedded resources + catalog + 3 text messages")
+				embedded, ok := result.Messages[2].Content.(*mcp.EmbeddedResource)
+				require.True(t, ok, "pasted catalog should be an embedded resource")
+				assert.Equal(t, pastedCatalogURI, embedded.Resource.URI)
+				assert.Equal(t, strings.TrimSpace(pastedCatalog), embedded.Resource.Text)
+			},
+		},
+		{
+			name: "pasted artifact of another type rejected",
+			arguments: map[string]string{
+				"component": "object storage",
+				"id_prefix": "ACME.PLAT.OS",
+				"catalog":   "metadata:\n  id: TC\n  type: ThreatCatalog\n",
+			},
+			wantErr:     true,
+			errContains: "catalog argument is a ThreatCatalog, not a ControlCatalog",
+		},
+		{
+			name: "catalog that is neither URL nor YAML rejected",
+			arguments: map[string]string{
+				"component": "object storage",
+				"id_prefix": "ACME.PLAT.OS",
+				"catalog":   "controls: [unterminated",
+			},
+			wantErr:     true,
+			errContains: "neither an http(s) URL nor YAML content",
+		},
+		{
+			name: "oversized catalog rejected",
+			arguments: map[string]string{
+				"component": "object storage",
+				"id_prefix": "ACME.PLAT.OS",
+				"catalog":   strings.Repeat("a", maxCatalogArgLen+1),
+			},
+			wantErr:     true,
+			errContains: "catalog argument exceeds maximum length",
+		},
+		{
+			name: "missing id_prefix argument",
+			arguments: map[string]string{
+				"component": "object storage",
+			},
+			wantErr:     true,
+			errContains: "id_prefix",
+		},
+		{
+			name: "component with template interpolation rejected",
+			arguments: map[string]string{
+				"component": "${EVIL_VAR}",
+				"id_prefix": "ACME.PLAT.OS",
+			},
+			wantErr:     true,
+			errContains: "must match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			req := &mcp.GetPromptRequest{
+				Params: &mcp.GetPromptParams{
+					Name:      "evaluation",
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handler(ctx, req)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			if tt.validateResult != nil {
+				tt.validateResult(t, result)
+			}
+		})
+	}
+}
+
 func TestNewMigrationHandler(t *testing.T) {
 	handler := NewMigrationHandler(mockLexiconFetcher, mockSchemaFetcher)
 
@@ -755,3 +1300,69 @@ func TestPromptThreatAssessmentMetadata(t *testing.T) {
 	assert.Equal(t, "id_prefix", prefixArg.Name)
 	assert.True(t, prefixArg.Required)
 }
+
+func TestWizardPrompts(t *testing.T) {
+	tests := []struct {
+		name       string
+		prompt     *mcp.Prompt
+		newHandler func(LexiconFetcher, SchemaDocsFetcher) mcp.PromptHandler
+		arguments  map[string]string
+		wantArgs   []string
+		optional   []string
+	}{
+		{
+			name:       "capability_catalog",
+			prompt:     PromptCapabilityCatalog,
+			newHandler: NewCapabilityCatalogHandler,
+			arguments:  map[string]string{"component": "test", "id_prefix": "ACME.TEST"},
+			wantArgs:   []string{"component", "id_prefix"},
+		},
+		{
+			name:       "guidance_catalog",
+			prompt:     PromptGuidanceCatalog,
+			newHandler: NewGuidanceCatalogHandler,
+			arguments:  map[string]string{"framework": "test", "id_prefix": "ACME.TEST"},
+			wantArgs:   []string{"framework", "id_prefix"},
+		},
+		{
+			name:       "policy",
+			prompt:     PromptPolicy,
+			newHandler: NewPolicyHandler,
+			arguments:  map[string]string{"component": "test", "id_prefix": "ACME.TEST"},
+			wantArgs:   []string{"component", "id_prefix"},
+		},
+		{
+			name:       "evaluation",
+			prompt:     PromptEvaluation,
+			newHandler: NewEvaluationHandler,
+			arguments:  map[string]string{"component": "test", "id_prefix": "ACME.TEST"},
+			wantArgs:   []string{"component", "id_prefix", "catalog"},
+			optional:   []string{"catalog"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, tt.prompt.Name)
+			assert.NotEmpty(t, tt.prompt.Description)
+			require.Len(t, tt.prompt.Arguments, len(tt.wantArgs))
+			for i, name := range tt.wantArgs {
+				arg := tt.prompt.Arguments[i]
+				assert.Equal(t, name, arg.Name)
+				assert.Equal(t, !slices.Contains(tt.optional, name), arg.Required, "argument %s", name)
+			}
+
+			req := &mcp.GetPromptRequest{
+				Params: &mcp.GetPromptParams{Name: tt.name, Arguments: tt.arguments},
+			}
+
+			_, err := tt.newHandler(failingLexiconFetcher, mockSchemaFetcher)(context.Background(), req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "fetching lexicon")
+
+			_, err = tt.newHandler(mockLexiconFetcher, failingSchemaFetcher)(context.Background(), req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "fetching schema docs")
+			assert.Contains(t, err.Error(), "network error")
+		})
+	}
+}
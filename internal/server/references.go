@@ -0,0 +1,477 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataCheckGemaraReferences describes the CheckGemaraReferences tool.
+var MetadataCheckGemaraReferences = &mcp.Tool{
+	Name:        "check_gemara_references",
+	Description: "Check referential integrity across a set of Gemara artifacts (ThreatCatalog, CapabilityCatalog, ControlCatalog, GuidanceCatalog, Policy). Resolves every mapping reference, import entry and threat/capability linkage against the artifacts in the set of the type the field points at (e.g. controls[].threats against ThreatCatalogs) and reports dangling references, unused mapping-references and duplicate IDs. References to artifacts outside the set are listed but not checked.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifacts": map[string]interface{}{
+				"type":        "array",
+				"description": "Artifacts to check together",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"artifact_content"},
+					"properties": map[string]interface{}{
+						"artifact_content": map[string]interface{}{
+							"type":        "string",
+							"description": "YAML content of the Gemara artifact",
+						},
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Label used to identify the artifact in issues, such as a file name",
+						},
+					},
+				},
+			},
+			"artifact_stream": map[string]interface{}{
+				"type":        "string",
+				"description": "Multi-document YAML stream of Gemara artifacts separated by '---'",
+			},
+		},
+	},
+}
+
+// ReferenceIssueKind classifies a ReferenceIssue.
+type ReferenceIssueKind string
+
+const (
+	// IssueDanglingReference marks a reference to an ID that is not defined.
+	IssueDanglingReference ReferenceIssueKind = "dangling-reference"
+	// IssueUnusedMappingReference marks a mapping-reference that nothing refers to.
+	IssueUnusedMappingReference ReferenceIssueKind = "unused-mapping-reference"
+	// IssueDuplicateID marks an ID defined more than once.
+	IssueDuplicateID ReferenceIssueKind = "duplicate-id"
+	// IssueUnreadableArtifact marks a document that is not valid YAML.
+	IssueUnreadableArtifact ReferenceIssueKind = "unreadable-artifact"
+)
+
+// InputCheckGemaraReferences is the input for the CheckGemaraReferences tool.
+type InputCheckGemaraReferences struct {
+	Artifacts      []BatchArtifact `json:"artifacts,omitempty"`
+	ArtifactStream string          `json:"artifact_stream,omitempty"`
+}
+
+// ReferenceIssue is a single referential integrity finding.
+type ReferenceIssue struct {
+	Kind     ReferenceIssueKind `json:"kind"`
+	Artifact string             `json:"artifact"`
+	// Path is the field path within the artifact, e.g. controls[0].threats[0].entries[1].reference-id.
+	Path string `json:"path,omitempty"`
+	// Line and Column locate the field in the submitted YAML (the stream, for
+	// documents of artifact_stream). Zero when unknown.
+	Line     int             `json:"line,omitempty"`
+	Column   int             `json:"column,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Severity schema.Severity `json:"severity"`
+	Message  string          `json:"message"`
+}
+
+// OutputCheckGemaraReferences is the output for the CheckGemaraReferences tool.
+type OutputCheckGemaraReferences struct {
+	// Valid is false when any issue has error severity.
+	Valid  bool             `json:"valid"`
+	Issues []ReferenceIssue `json:"issues,omitempty"`
+	// External lists mapping-references to artifacts that are not in the set;
+	// entries referring to them are not checked.
+	External []string `json:"external,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// CheckGemaraReferences resolves the references between the submitted artifacts.
+// The returned *mcp.CallToolResult is always nil; the go-sdk derives the
+// tool response from the OutputCheckGemaraReferences struct.
+func CheckGemaraReferences(_ context.Context, _ *mcp.CallToolRequest, input InputCheckGemaraReferences) (*mcp.CallToolResult, OutputCheckGemaraReferences, error) {
+	docs := batchDocuments(InputValidateGemaraArtifacts{Artifacts: input.Artifacts, ArtifactStream: input.ArtifactStream})
+	if len(docs) == 0 {
+		return nil, OutputCheckGemaraReferences{}, fmt.Errorf("artifacts or artifact_stream is required")
+	}
+	if len(docs) > maxBatchDocuments {
+		return nil, OutputCheckGemaraReferences{}, fmt.Errorf("too many documents: %d exceeds the limit of %d", len(docs), maxBatchDocuments)
+	}
+
+	slog.Info("checking artifact references", "documents", len(docs))
+
+	c := &referenceChecker{}
+	for _, doc := range docs {
+		c.add(doc)
+	}
+	c.check()
+
+	var errorCount, warningCount int
+	for _, issue := range c.issues {
+		if issue.Severity == schema.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	slog.Info("reference check complete", "documents", len(docs), "errors", errorCount, "warnings", warningCount)
+	return nil, OutputCheckGemaraReferences{
+		Valid:    errorCount == 0,
+		Issues:   c.issues,
+		External: c.external,
+		Message:  fmt.Sprintf("%d errors, %d warnings across %d artifacts", errorCount, warningCount, len(docs)),
+	}, nil
+}
+
+// refSite is an ID and the field path where it occurs.
+type refSite struct {
+	id   string
+	path string
+}
+
+// refMapping is a use of a mapping-reference together with the IDs of the
+// entries it points at within the referenced artifact.
+type refMapping struct {
+	refSite
+	// target is the artifact type the entries are defined in, or empty when
+	// the field does not determine it.
+	target  string
+	entries []refSite
+}
+
+// mappingTargets names the artifact type whose entries a mapping field
+// points at. Top-level imports point at artifacts of the importing type.
+var mappingTargets = map[string]string{
+	"threats":      "ThreatCatalog",
+	"capabilities": "CapabilityCatalog",
+	"guidelines":   "GuidanceCatalog",
+	"catalogs":     "ControlCatalog",
+	"guidance":     "GuidanceCatalog",
+}
+
+// artifactKey identifies an artifact by its metadata.type and metadata.id;
+// artifacts of different types may share an id.
+type artifactKey struct {
+	typ string
+	id  string
+}
+
+// refArtifact holds the IDs an artifact defines and the references it makes.
+type refArtifact struct {
+	name string
+	line int
+	file *ast.File
+
+	id  string
+	typ string
+
+	mappingRefs []refSite
+	groups      []refSite
+	// entities are the ids of list items outside metadata and groups, such
+	// as threats, capabilities, controls and assessment requirements.
+	entities  []refSite
+	mappings  []refMapping
+	groupUses []refSite
+}
+
+// referenceChecker accumulates artifacts and the issues found between them.
+type referenceChecker struct {
+	artifacts []*refArtifact
+	issues    []ReferenceIssue
+	external  []string
+}
+
+// add parses a document and records what it defines and references.
+func (c *referenceChecker) add(doc batchDocument) {
+	a := &refArtifact{name: doc.name, line: doc.line}
+
+	file, err := parser.ParseBytes([]byte(doc.content), 0)
+	if err != nil {
+		c.issues = append(c.issues, ReferenceIssue{
+			Kind:     IssueUnreadableArtifact,
+			Artifact: doc.name,
+			Severity: schema.SeverityError,
+			Message:  fmt.Sprintf("invalid YAML: %v", err),
+		})
+		return
+	}
+	a.file = file
+
+	var root yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(doc.content), &root, yaml.UseOrderedMap()); err != nil {
+		c.issues = append(c.issues, ReferenceIssue{
+			Kind:     IssueUnreadableArtifact,
+			Artifact: doc.name,
+			Severity: schema.SeverityError,
+			Message:  fmt.Sprintf("artifact is not a YAML mapping: %v", err),
+		})
+		return
+	}
+
+	for _, item := range root {
+		key := fmt.Sprint(item.Key)
+		switch key {
+		case "metadata":
+			meta, _ := item.Value.(yaml.MapSlice)
+			a.id, _ = stringField(meta, "id")
+			a.typ, _ = stringField(meta, "type")
+			a.mappingRefs = listIDs(mapField(meta, "mapping-references"), "metadata.mapping-references")
+		case "groups":
+			a.groups = listIDs(item.Value, "groups")
+		default:
+			a.walk(item.Value, key)
+		}
+	}
+	c.artifacts = append(c.artifacts, a)
+}
+
+// walk records the entities, mappings and group uses below value.
+func (a *refArtifact) walk(value any, path string) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		if ref, ok := stringField(v, "reference-id"); ok {
+			m := refMapping{refSite: refSite{id: ref, path: path + ".reference-id"}, target: a.mappingTarget(path)}
+			entries, _ := mapField(v, "entries").([]any)
+			for i, e := range entries {
+				entry, _ := e.(yaml.MapSlice)
+				if id, ok := stringField(entry, "reference-id"); ok {
+					m.entries = append(m.entries, refSite{id: id, path: fmt.Sprintf("%s.entries[%d].reference-id", path, i)})
+				}
+			}
+			a.mappings = append(a.mappings, m)
+			return
+		}
+		for _, item := range v {
+			key := fmt.Sprint(item.Key)
+			if group, ok := item.Value.(string); ok && key == "group" {
+				a.groupUses = append(a.groupUses, refSite{id: group, path: path + ".group"})
+			}
+			a.walk(item.Value, path+"."+key)
+		}
+	case []any:
+		for i, e := range v {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if m, ok := e.(yaml.MapSlice); ok {
+				if id, ok := stringField(m, "id"); ok {
+					a.entities = append(a.entities, refSite{id: id, path: elemPath + ".id"})
+				}
+			}
+			a.walk(e, elemPath)
+		}
+	}
+}
+
+// mappingTarget returns the artifact type referenced by the mapping at path,
+// such as controls[0].threats[1], or "" when the field does not determine it.
+func (a *refArtifact) mappingTarget(path string) string {
+	field := path
+	if i := strings.LastIndexByte(field, '['); i >= 0 {
+		field = field[:i]
+	}
+	if field == "imports" {
+		return a.typ
+	}
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+	return mappingTargets[field]
+}
+
+// check resolves every recorded reference across the artifact set.
+func (c *referenceChecker) check() {
+	byID := make(map[string][]*refArtifact)
+	defined := make(map[artifactKey]map[string]bool) // artifact -> entity ids
+	for _, a := range c.artifacts {
+		if a.id == "" {
+			continue
+		}
+		byID[a.id] = append(byID[a.id], a)
+		key := artifactKey{typ: a.typ, id: a.id}
+		if defined[key] == nil {
+			defined[key] = make(map[string]bool)
+		}
+		for _, e := range a.entities {
+			defined[key][e.id] = true
+		}
+	}
+
+	c.checkDuplicates()
+
+	external := make(map[string]bool)
+	for _, a := range c.artifacts {
+		declared := make(map[string]bool)
+		for _, r := range a.mappingRefs {
+			declared[r.id] = true
+		}
+		used := make(map[string]bool)
+
+		for _, m := range a.mappings {
+			used[m.id] = true
+			if !declared[m.id] {
+				c.report(a, IssueDanglingReference, schema.SeverityError, m.refSite,
+					fmt.Sprintf("%s is not declared in metadata.mapping-references", m.id))
+				continue
+			}
+			var targets []artifactKey
+			for _, t := range byID[m.id] {
+				if m.target == "" || t.typ == m.target {
+					targets = append(targets, artifactKey{typ: t.typ, id: t.id})
+				}
+			}
+			if len(targets) == 0 {
+				if !external[m.id] {
+					external[m.id] = true
+					c.external = append(c.external, m.id)
+				}
+				continue
+			}
+			kind := "artifact"
+			if m.target != "" {
+				kind = m.target
+			}
+			for _, e := range m.entries {
+				if !slices.ContainsFunc(targets, func(k artifactKey) bool { return defined[k][e.id] }) {
+					c.report(a, IssueDanglingReference, schema.SeverityError, e,
+						fmt.Sprintf("%s is not defined by any %s with metadata.id %s", e.id, kind, m.id))
+				}
+			}
+		}
+
+		for _, r := range a.mappingRefs {
+			if !used[r.id] {
+				c.report(a, IssueUnusedMappingReference, schema.SeverityWarning, r,
+					fmt.Sprintf("mapping-reference %s is never referenced", r.id))
+			}
+		}
+
+		groups := make(map[string]bool)
+		for _, g := range a.groups {
+			groups[g.id] = true
+		}
+		for _, g := range a.groupUses {
+			if !groups[g.id] {
+				c.report(a, IssueDanglingReference, schema.SeverityError, g,
+					fmt.Sprintf("group %s is not declared in groups", g.id))
+			}
+		}
+	}
+}
+
+// checkDuplicates reports IDs defined more than once: entity IDs across the
+// whole set, metadata.id among artifacts of the same type, and
+// mapping-reference and group IDs within an artifact.
+func (c *referenceChecker) checkDuplicates() {
+	type origin struct {
+		artifact *refArtifact
+		path     string
+	}
+	entities := make(map[string]origin)
+	artifacts := make(map[string]*refArtifact)
+
+	for _, a := range c.artifacts {
+		if a.id != "" {
+			key := a.typ + "\x00" + a.id
+			if first, ok := artifacts[key]; ok {
+				c.report(a, IssueDuplicateID, schema.SeverityError, refSite{id: a.id, path: "metadata.id"},
+					fmt.Sprintf("metadata.id %s is already used by %s %s", a.id, a.typ, first.name))
+			} else {
+				artifacts[key] = a
+			}
+		}
+
+		for _, e := range a.entities {
+			if first, ok := entities[e.id]; ok {
+				c.report(a, IssueDuplicateID, schema.SeverityError, e,
+					fmt.Sprintf("id %s is already defined in %s at %s", e.id, first.artifact.name, first.path))
+				continue
+			}
+			entities[e.id] = origin{artifact: a, path: e.path}
+		}
+
+		for _, sites := range [][]refSite{a.mappingRefs, a.groups} {
+			seen := make(map[string]string)
+			for _, s := range sites {
+				if firstPath, ok := seen[s.id]; ok {
+					c.report(a, IssueDuplicateID, schema.SeverityError, s,
+						fmt.Sprintf("id %s is already defined at %s", s.id, firstPath))
+					continue
+				}
+				seen[s.id] = s.path
+			}
+		}
+	}
+}
+
+// report records an issue located at site within a.
+func (c *referenceChecker) report(a *refArtifact, kind ReferenceIssueKind, severity schema.Severity, site refSite, msg string) {
+	issue := ReferenceIssue{
+		Kind:     kind,
+		Artifact: a.name,
+		Path:     site.path,
+		ID:       site.id,
+		Severity: severity,
+		Message:  msg,
+	}
+	issue.Line, issue.Column = a.position(site.path)
+	c.issues = append(c.issues, issue)
+}
+
+// position returns the line and column of the field at path, relative to
+// the submitted stream. It returns zeros when the field cannot be located.
+func (a *refArtifact) position(path string) (int, int) {
+	p, err := yaml.PathString("$." + path)
+	if err != nil {
+		return 0, 0
+	}
+	node, err := p.FilterFile(a.file)
+	if err != nil || node == nil || node.GetToken() == nil {
+		return 0, 0
+	}
+	pos := node.GetToken().Position
+	line := pos.Line
+	if a.line > 1 {
+		line += a.line - 1
+	}
+	return line, pos.Column
+}
+
+// listIDs returns the id of every mapping in the list value, with paths
+// rooted at path.
+func listIDs(value any, path string) []refSite {
+	list, _ := value.([]any)
+	var sites []refSite
+	for i, e := range list {
+		m, _ := e.(yaml.MapSlice)
+		if id, ok := stringField(m, "id"); ok {
+			sites = append(sites, refSite{id: id, path: fmt.Sprintf("%s[%d].id", path, i)})
+		}
+	}
+	return sites
+}
+
+// mapField returns the value stored under key, or nil.
+func mapField(m yaml.MapSlice, key string) any {
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// stringField returns the non-empty string stored under key.
+func stringField(m yaml.MapSlice, key string) (string, bool) {
+	s, ok := mapField(m, key).(string)
+	s = strings.TrimSpace(s)
+	return s, ok && s != ""
+}
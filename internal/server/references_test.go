@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const refThreatCatalog = `metadata:
+  id: EX
+  type: ThreatCatalog
+  mapping-references:
+    - id: EX
+    - id: UNUSED
+groups:
+  - id: tampering
+threats:
+  - id: EX.THR01
+    group: tampering
+    capabilities:
+      - reference-id: EX
+        entries:
+          - reference-id: EX.CAP01
+          - reference-id: EX.CAP99
+  - id: EX.THR01
+    group: spoofing
+`
+
+const refControlCatalog = `metadata:
+  id: EX
+  type: ControlCatalog
+  mapping-references:
+    - id: EX
+    - id: EXTERNAL
+imports:
+  - reference-id: EXTERNAL
+    entries:
+      - reference-id: EXTERNAL.CN01
+controls:
+  - id: EX.C01
+    threats:
+      - reference-id: EX
+        entries:
+          - reference-id: EX.THR01
+      - reference-id: UNDECLARED
+        entries:
+          - reference-id: X
+`
+
+const refCapabilityCatalog = `metadata:
+  id: EX
+  type: CapabilityCatalog
+capabilities:
+  - id: EX.CAP01
+`
+
+func TestCheckGemaraReferences(t *testing.T) {
+	_, output, err := CheckGemaraReferences(context.Background(), nil, InputCheckGemaraReferences{
+		Artifacts: []BatchArtifact{
+			{Name: "threats.yaml", ArtifactContent: refThreatCatalog},
+			{Name: "controls.yaml", ArtifactContent: refControlCatalog},
+		},
+		ArtifactStream: "---\n" + refCapabilityCatalog,
+	})
+	require.NoError(t, err)
+	assert.False(t, output.Valid)
+	assert.Equal(t, []string{"EXTERNAL"}, output.External)
+
+	type issueKey struct {
+		kind     ReferenceIssueKind
+		artifact string
+		path     string
+		id       string
+	}
+	var got []issueKey
+	for _, issue := range output.Issues {
+		got = append(got, issueKey{issue.Kind, issue.Artifact, issue.Path, issue.ID})
+	}
+	assert.ElementsMatch(t, []issueKey{
+		{IssueDuplicateID, "threats.yaml", "threats[1].id", "EX.THR01"},
+		{IssueDanglingReference, "threats.yaml", "threats[0].capabilities[0].entries[1].reference-id", "EX.CAP99"},
+		{IssueUnusedMappingReference, "threats.yaml", "metadata.mapping-references[1].id", "UNUSED"},
+		{IssueDanglingReference, "threats.yaml", "threats[1].group", "spoofing"},
+		{IssueDanglingReference, "controls.yaml", "controls[0].threats[1].reference-id", "UNDECLARED"},
+	}, got)
+
+	for _, issue := range output.Issues {
+		if issue.Kind == IssueUnusedMappingReference {
+			assert.Equal(t, schema.SeverityWarning, issue.Severity)
+			continue
+		}
+		assert.Equal(t, schema.SeverityError, issue.Severity)
+		if issue.ID == "EX.CAP99" {
+			assert.Equal(t, 16, issue.Line, "line should point at the dangling entry")
+		}
+	}
+}
+
+func TestCheckGemaraReferencesResolvesByArtifactType(t *testing.T) {
+	controls := `metadata:
+  id: EX
+  type: ControlCatalog
+  mapping-references:
+    - id: EX
+controls:
+  - id: EX.C01
+    threats:
+      - reference-id: EX
+        entries:
+          - reference-id: EX.THR01
+          - reference-id: EX.CAP01
+    guidelines:
+      - reference-id: EX
+        entries:
+          - reference-id: EX.THR01
+`
+	_, output, err := CheckGemaraReferences(context.Background(), nil, InputCheckGemaraReferences{
+		ArtifactStream: controls + "---\n" + refCapabilityCatalog + "---\nmetadata:\n  id: EX\n  type: ThreatCatalog\nthreats:\n  - id: EX.THR01\n",
+	})
+	require.NoError(t, err)
+	assert.False(t, output.Valid)
+	assert.Equal(t, []string{"EX"}, output.External, "no GuidanceCatalog EX is in the set")
+	require.Len(t, output.Issues, 1)
+	issue := output.Issues[0]
+	assert.Equal(t, IssueDanglingReference, issue.Kind)
+	assert.Equal(t, "controls[0].threats[0].entries[1].reference-id", issue.Path)
+	assert.Equal(t, "EX.CAP01 is not defined by any ThreatCatalog with metadata.id EX", issue.Message,
+		"a capability of the CapabilityCatalog with the same metadata.id must not satisfy a threat mapping")
+}
+
+func TestCheckGemaraReferencesStreamLines(t *testing.T) {
+	stream := refCapabilityCatalog + "---\nmetadata:\n  id: OTHER\n  type: CapabilityCatalog\ncapabilities:\n  - id: EX.CAP01\n"
+	_, output, err := CheckGemaraReferences(context.Background(), nil, InputCheckGemaraReferences{ArtifactStream: stream})
+	require.NoError(t, err)
+	require.Len(t, output.Issues, 1)
+	assert.Equal(t, IssueDuplicateID, output.Issues[0].Kind)
+	assert.Equal(t, "document 2", output.Issues[0].Artifact)
+	assert.Equal(t, 11, output.Issues[0].Line)
+}
+
+func TestCheckGemaraReferencesInvalidInput(t *testing.T) {
+	_, _, err := CheckGemaraReferences(context.Background(), nil, InputCheckGemaraReferences{})
+	require.ErrorContains(t, err, "artifacts or artifact_stream is required")
+
+	_, output, err := CheckGemaraReferences(context.Background(), nil, InputCheckGemaraReferences{
+		Artifacts: []BatchArtifact{{ArtifactContent: "metadata: [unclosed"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, output.Valid)
+	require.Len(t, output.Issues, 1)
+	assert.Equal(t, IssueUnreadableArtifact, output.Issues[0].Kind)
+}
+
+// TestCheckGemaraReferencesRepoArtifacts keeps the repository's own security
+// artifacts free of dangling references and duplicate IDs.
+func TestCheckGemaraReferencesRepoArtifacts(t *testing.T) {
+	var artifacts []BatchArtifact
+	for _, name := range []string{"threats.yaml", "capabilities.yaml", "controls.yaml"} {
+		content, err := os.ReadFile(filepath.Join("..", "..", ".github", name))
+		require.NoError(t, err)
+		artifacts = append(artifacts, BatchArtifact{Name: name, ArtifactContent: string(content)})
+	}
+
+	_, output, err := CheckGemaraReferences(context.Background(), nil, InputCheckGemaraReferences{Artifacts: artifacts})
+	require.NoError(t, err)
+	for _, issue := range output.Issues {
+		assert.NotEqual(t, schema.SeverityError, issue.Severity, "%s: %s: %s", issue.Artifact, issue.Path, issue.Message)
+	}
+	assert.True(t, output.Valid)
+}
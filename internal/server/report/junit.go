@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit encodes the findings of artifacts as a JUnit XML report with one
+// test case per artifact, named after its file. A test case fails when the
+// artifact has an error; its failure is typed with the rule of the first
+// error and lists every finding as file:line:column: [rule] message.
+// Warnings alone are listed in the test case's output.
+func WriteJUnit(w io.Writer, artifacts []Artifact) error {
+	suite := junitTestSuite{Name: toolName}
+	for _, a := range artifacts {
+		tc := junitTestCase{Name: a.Path, ClassName: a.Rule.ID, File: a.Path}
+
+		var lines []string
+		var first *finding
+		for _, f := range a.findings() {
+			lines = append(lines, junitLine(a.Path, f))
+			if first == nil && f.Severity != schema.SeverityWarning {
+				first = &f
+			}
+		}
+		switch {
+		case first != nil:
+			tc.Failure = &junitFailure{
+				Message: first.text(),
+				Type:    first.rule.ID,
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		case len(lines) > 0:
+			tc.SystemOut = strings.Join(lines, "\n")
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err := enc.Encode(junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding JUnit: %w", err)
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// junitLine renders a finding as file:line:column: [rule] message.
+func junitLine(path string, f finding) string {
+	location := path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", path, f.Line, f.Column)
+	}
+	return fmt.Sprintf("%s: [%s] %s", location, f.rule.ID, f.text())
+}
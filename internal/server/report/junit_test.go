@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	artifacts := []Artifact{
+		{Path: "valid.yaml", Rule: RuleSchema},
+		{
+			Path: "controls.yaml",
+			Rule: RuleSchema,
+			Diagnostics: []schema.Diagnostic{
+				{Path: "title", Line: 1, Column: 1, Severity: schema.SeverityError, Rule: schema.RuleMissingField, Message: "missing required field"},
+				{Path: "extra", Line: 9, Column: 1, Severity: schema.SeverityError, Rule: schema.RuleFieldNotAllowed, Message: "field not allowed"},
+			},
+		},
+		{Path: "broken.yaml", Rule: RuleSchema, Errors: []string{"loading schema: not found"}},
+		{
+			Path:        "out/controls.yaml",
+			Rule:        RuleMigrationTODO,
+			Diagnostics: []schema.Diagnostic{{Path: "metadata.id", Line: 2, Column: 7, Severity: schema.SeverityWarning, Message: "needs a value"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, artifacts))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	require.Len(t, suites.Suites, 1)
+	cases := suites.Suites[0].Cases
+	require.Len(t, cases, 4)
+
+	assert.Nil(t, cases[0].Failure)
+	assert.Equal(t, "gemara/schema", cases[0].ClassName)
+
+	require.NotNil(t, cases[1].Failure)
+	assert.Equal(t, "gemara/missing-field", cases[1].Failure.Type)
+	assert.Equal(t, "title: missing required field", cases[1].Failure.Message)
+	assert.Equal(t, "controls.yaml:1:1: [gemara/missing-field] title: missing required field\n"+
+		"controls.yaml:9:1: [gemara/field-not-allowed] extra: field not allowed", cases[1].Failure.Text)
+
+	require.NotNil(t, cases[2].Failure)
+	assert.Equal(t, "broken.yaml: [gemara/schema] loading schema: not found", cases[2].Failure.Text)
+
+	assert.Nil(t, cases[3].Failure, "warnings do not fail the test case")
+	assert.Equal(t, "out/controls.yaml:2:7: [gemara/migration-todo] metadata.id: needs a value", cases[3].SystemOut)
+}
+
+func TestWrite(t *testing.T) {
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, format, "v1.0.0", []Artifact{{Path: "a.yaml", Rule: RuleSchema}}), format)
+		assert.NotEmpty(t, buf.String())
+	}
+	require.ErrorContains(t, Write(&bytes.Buffer{}, "xml", "", nil), "unknown report format")
+}
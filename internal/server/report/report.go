@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package report encodes validation results in formats understood by CI
+// systems and code-scanning tools.
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+)
+
+const toolName = "gemara-mcp"
+
+// Report formats.
+const (
+	// FormatSARIF is a SARIF 2.1.0 log, for code-scanning annotations.
+	FormatSARIF = "sarif"
+	// FormatJUnit is a JUnit XML report, for CI test reports.
+	FormatJUnit = "junit"
+)
+
+// Formats lists the supported report formats.
+var Formats = []string{FormatSARIF, FormatJUnit}
+
+// Rule identifies the check that produced a finding.
+type Rule struct {
+	ID          string
+	Description string
+}
+
+var (
+	// RuleSchema reports artifacts that do not conform to their Gemara
+	// schema definition, for findings no more specific rule covers.
+	RuleSchema = Rule{ID: "gemara/schema", Description: "Artifact conforms to its Gemara schema definition"}
+	// RuleMigrationTODO reports placeholders a migration left for the author
+	// to fill in.
+	RuleMigrationTODO = Rule{ID: "gemara/migration-todo", Description: "Migrated artifact has no REPLACE ME placeholders"}
+)
+
+// schemaRules maps the kind of schema check a diagnostic failed to its rule.
+var schemaRules = map[schema.Rule]Rule{
+	schema.RuleYAMLSyntax:      {ID: "gemara/yaml-syntax", Description: "Artifact is well-formed YAML"},
+	schema.RuleMissingField:    {ID: "gemara/missing-field", Description: "Fields required by the schema are present"},
+	schema.RuleFieldNotAllowed: {ID: "gemara/field-not-allowed", Description: "Only fields declared by the schema are used"},
+	schema.RuleTypeMismatch:    {ID: "gemara/type-mismatch", Description: "Values have the type the schema requires"},
+	schema.RuleInvalidValue:    {ID: "gemara/invalid-value", Description: "Values satisfy the schema's patterns, bounds and enumerations"},
+}
+
+// Artifact holds the findings for one artifact file.
+type Artifact struct {
+	// Path is the file the findings are reported against.
+	Path string
+	// Rule is the rule of findings whose diagnostic does not name a more
+	// specific one.
+	Rule        Rule
+	Diagnostics []schema.Diagnostic
+	// Errors are reported without a location when there are no Diagnostics,
+	// e.g. when no schema definition matched the artifact.
+	Errors []string
+}
+
+// finding is a diagnostic with the rule it is reported under.
+type finding struct {
+	rule Rule
+	schema.Diagnostic
+}
+
+// findings returns the findings of a, each under the rule of its diagnostic.
+func (a Artifact) findings() []finding {
+	var result []finding
+	for _, d := range a.Diagnostics {
+		rule, ok := schemaRules[d.Rule]
+		if !ok {
+			rule = a.Rule
+		}
+		result = append(result, finding{rule: rule, Diagnostic: d})
+	}
+	if len(result) == 0 {
+		for _, e := range a.Errors {
+			result = append(result, finding{rule: a.Rule, Diagnostic: schema.Diagnostic{Severity: schema.SeverityError, Message: e}})
+		}
+	}
+	return result
+}
+
+// text is the message of the finding, prefixed with its field path.
+func (f finding) text() string {
+	if f.Path == "" {
+		return f.Message
+	}
+	return f.Path + ": " + f.Message
+}
+
+// Write encodes the findings of artifacts in format. toolVersion is recorded
+// where the format has a place for it.
+func Write(w io.Writer, format, toolVersion string, artifacts []Artifact) error {
+	switch format {
+	case FormatSARIF:
+		return WriteSARIF(w, toolVersion, artifacts)
+	case FormatJUnit:
+		return WriteJUnit(w, artifacts)
+	default:
+		return fmt.Errorf("unknown report format %q: must be \"sarif\" or \"junit\"", format)
+	}
+}
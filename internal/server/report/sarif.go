@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+)
+
+const (
+	informationURI = "https://github.com/gemaraproj/gemara-mcp"
+	sarifVersion   = "2.1.0"
+	sarifSchema    = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF encodes the findings of artifacts as a SARIF 2.1.0 log with a
+// single run of gemara-mcp at toolVersion. Each finding is a result under its
+// rule, located at the artifact's file and, when known, its line and column.
+func WriteSARIF(w io.Writer, toolVersion string, artifacts []Artifact) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			Version:        toolVersion,
+			InformationURI: informationURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+
+	for _, a := range artifacts {
+		for _, f := range a.findings() {
+			index, ok := ruleIndex[f.rule.ID]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				ruleIndex[f.rule.ID] = index
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:               f.rule.ID,
+					ShortDescription: sarifMessage{Text: f.rule.Description},
+				})
+			}
+
+			location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(a.Path)}}
+			if f.Line > 0 {
+				location.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    f.rule.ID,
+				RuleIndex: index,
+				Level:     sarifLevel(f.Severity),
+				Message:   sarifMessage{Text: f.text()},
+				Locations: []sarifLocation{{PhysicalLocation: location}},
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("encoding SARIF: %w", err)
+	}
+	return nil
+}
+
+// sarifLevel maps a diagnostic severity to a SARIF result level.
+func sarifLevel(s schema.Severity) string {
+	if s == schema.SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
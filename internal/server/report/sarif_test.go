@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	artifacts := []Artifact{
+		{Path: "valid.yaml", Rule: RuleSchema},
+		{
+			Path: "catalogs/controls.yaml",
+			Rule: RuleSchema,
+			Diagnostics: []schema.Diagnostic{
+				{Path: "metadata.id", Line: 2, Column: 7, Severity: schema.SeverityError, Rule: schema.RuleTypeMismatch, Message: "conflicting values"},
+				{Path: "title", Severity: schema.SeverityError, Message: "missing required field"},
+			},
+			Errors: []string{"ignored in favor of diagnostics"},
+		},
+		{Path: "unknown.yaml", Rule: RuleSchema, Errors: []string{"artifact does not match any schema definition"}},
+		{
+			Path:        "out/controls.yaml",
+			Rule:        RuleMigrationTODO,
+			Diagnostics: []schema.Diagnostic{{Path: "metadata.author.id", Line: 5, Severity: schema.SeverityWarning, Message: "needs a value"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, "v1.2.3", artifacts))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "gemara-mcp", run.Tool.Driver.Name)
+	assert.Equal(t, "v1.2.3", run.Tool.Driver.Version)
+	assert.Equal(t, []sarifRule{
+		{ID: "gemara/type-mismatch", ShortDescription: sarifMessage{Text: schemaRules[schema.RuleTypeMismatch].Description}},
+		{ID: "gemara/schema", ShortDescription: sarifMessage{Text: RuleSchema.Description}},
+		{ID: "gemara/migration-todo", ShortDescription: sarifMessage{Text: RuleMigrationTODO.Description}},
+	}, run.Tool.Driver.Rules)
+
+	require.Len(t, run.Results, 4)
+	assert.Equal(t, sarifResult{
+		RuleID:  "gemara/type-mismatch",
+		Level:   "error",
+		Message: sarifMessage{Text: "metadata.id: conflicting values"},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "catalogs/controls.yaml"},
+			Region:           &sarifRegion{StartLine: 2, StartColumn: 7},
+		}}},
+	}, run.Results[0])
+	assert.Equal(t, "gemara/schema", run.Results[1].RuleID, "unclassified findings fall back to the artifact's rule")
+	assert.Nil(t, run.Results[1].Locations[0].PhysicalLocation.Region, "findings without a line have no region")
+	assert.Equal(t, "artifact does not match any schema definition", run.Results[2].Message.Text)
+	assert.Equal(t, "warning", run.Results[3].Level)
+	assert.Equal(t, 2, run.Results[3].RuleIndex)
+}
+
+func TestWriteSARIFNoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, "", []Artifact{{Path: "valid.yaml", Rule: RuleSchema}}))
+	assert.Contains(t, buf.String(), `"results": []`)
+	assert.Contains(t, buf.String(), `"rules": []`)
+}
@@ -8,8 +8,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"strings"
 
-	"cuelang.org/go/cue"
 	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
 	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -38,7 +38,7 @@ var ResourceSchemaDocsTemplate = &mcp.ResourceTemplate{
 	URITemplate: SchemaDocsResourceURITemplate,
 	Name:        "gemara-schema-docs-versioned",
 	Title:       "Gemara Schema Documentation (versioned)",
-	Description: "CUE schema definitions for a specific Gemara module version. Accepts a semver version parameter (e.g., v1.2.3) or 'latest'.",
+	Description: "CUE schema definitions for a specific Gemara module version. Accepts a semver version parameter (e.g., v1.2.3) or 'latest', and an optional definition parameter (e.g., #Policy) to return a single top-level definition.",
 	MIMEType:    "text/plain",
 }
 
@@ -70,6 +70,7 @@ func (a *AdvisoryMode) fetchLexicon(ctx context.Context) (content string, source
 	}
 
 	cf := fetcher.NewCachedFetcher[[]byte](hf, a.lexiconCache, hf.URL())
+	cf.StaleWhileRevalidate = a.staleWhileRevalidate
 	data, src, err := cf.Fetch(ctx, false)
 	if err != nil {
 		slog.Warn("failed to fetch lexicon, using embedded fallback", "error", err)
@@ -90,7 +91,7 @@ func (a *AdvisoryMode) resolveLexiconVersion(ctx context.Context) (string, error
 }
 
 func (a *AdvisoryMode) handleSchemaDocsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	return a.fetchSchemaDocsForVersion(ctx, req.Params.URI, defaultSchemaVersion)
+	return a.fetchSchemaDocsForVersion(ctx, req.Params.URI, defaultSchemaVersion, "")
 }
 
 func (a *AdvisoryMode) handleSchemaDocsTemplateResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
@@ -98,25 +99,32 @@ func (a *AdvisoryMode) handleSchemaDocsTemplateResource(ctx context.Context, req
 	if err != nil {
 		return nil, err
 	}
-	return a.fetchSchemaDocsForVersion(ctx, req.Params.URI, version)
+	definition, err := parseSchemaDocsDefinition(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	return a.fetchSchemaDocsForVersion(ctx, req.Params.URI, version, definition)
 }
 
-func (a *AdvisoryMode) fetchSchemaDocsForVersion(ctx context.Context, uri, version string) (*mcp.ReadResourceResult, error) {
-	modulePath := gemaraModuleBase + version
-	f := schema.NewCUERegistryFetcher(modulePath)
-	cf := fetcher.NewCachedFetcher[cue.Value](f, a.schemaCache, modulePath)
-
-	val, source, err := cf.Fetch(ctx, false)
+// fetchSchemaDocsForVersion formats the schema at version, or only its
+// top-level definition when definition is set.
+func (a *AdvisoryMode) fetchSchemaDocsForVersion(ctx context.Context, uri, version, definition string) (*mcp.ReadResourceResult, error) {
+	val, source, err := a.schemaFetcher(version).Fetch(ctx, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch schema: %w", err)
 	}
 
-	defs, err := schema.FormatDefinitions(val)
+	var defs string
+	if definition != "" {
+		defs, err = schema.FormatDefinition(val, definition)
+	} else {
+		defs, err = schema.FormatDefinitions(val)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to format schema: %w", err)
 	}
 
-	slog.Info("schema docs resource read", "version", version, "source", source, "size", len(defs))
+	slog.Info("schema docs resource read", "version", version, "definition", definition, "source", source, "size", len(defs))
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{{
 			URI:      uri,
@@ -142,3 +150,17 @@ func parseSchemaDocsVersion(rawURI string) (string, error) {
 	}
 	return version, nil
 }
+
+// parseSchemaDocsDefinition extracts the optional definition query parameter
+// from a schema docs resource URI, adding the leading '#' when it is omitted.
+func parseSchemaDocsDefinition(rawURI string) (string, error) {
+	u, err := url.Parse(rawURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource URI: %w", err)
+	}
+	definition := u.Query().Get("definition")
+	if definition == "" || strings.HasPrefix(definition, "#") {
+		return definition, nil
+	}
+	return "#" + definition, nil
+}
@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/mod/modconfig"
+	"cuelang.org/go/mod/modfile"
+	"cuelang.org/go/mod/modregistry"
+	"cuelang.org/go/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Bundle is a read-only CUE module registry backed by pre-downloaded module
+// versions on disk, for validating artifacts and resolving schema versions
+// without network access (e.g. on air-gapped build agents).
+//
+// Each module version is stored in its own directory, named by the module
+// path without its major version suffix, "@", and the version:
+//
+//	<root>/github.com/gemaraproj/gemara@v1.0.0/cue.mod/module.cue
+type Bundle struct {
+	root    string
+	cleanup func() error
+}
+
+var _ modconfig.Registry = (*Bundle)(nil)
+
+// OpenBundle opens a schema bundle from a directory or a tarball
+// (.tar, .tar.gz or .tgz). Tarballs are extracted to a temporary directory
+// that is removed by Close.
+func OpenBundle(bundlePath string) (*Bundle, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening schema bundle: %w", err)
+	}
+	if info.IsDir() {
+		return &Bundle{root: bundlePath}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gemara-schema-bundle-")
+	if err != nil {
+		return nil, fmt.Errorf("creating bundle directory: %w", err)
+	}
+	if err := extractTarball(bundlePath, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("extracting schema bundle %s: %w", bundlePath, err)
+	}
+	return &Bundle{root: dir, cleanup: func() error { return os.RemoveAll(dir) }}, nil
+}
+
+// Root returns the directory the bundle is served from.
+func (b *Bundle) Root() string {
+	return b.root
+}
+
+// Close releases any temporary files created when opening the bundle.
+func (b *Bundle) Close() error {
+	if b.cleanup == nil {
+		return nil
+	}
+	return b.cleanup()
+}
+
+// Requirements returns the dependencies declared in the module file of the
+// given module version.
+func (b *Bundle) Requirements(_ context.Context, mv module.Version) ([]module.Version, error) {
+	modFilePath := filepath.Join(b.moduleDir(mv), "cue.mod", "module.cue")
+	data, err := os.ReadFile(modFilePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("module %v not in schema bundle: %w", mv, modregistry.ErrNotFound)
+		}
+		return nil, err
+	}
+	mf, err := modfile.Parse(data, mv.String())
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse module file from %v: %w", mv, err)
+	}
+	return mf.DepVersions(), nil
+}
+
+// Fetch returns the location of the given module version in the bundle.
+func (b *Bundle) Fetch(_ context.Context, mv module.Version) (module.SourceLoc, error) {
+	dir := b.moduleDir(mv)
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return module.SourceLoc{}, fmt.Errorf("module %v not in schema bundle: %w", mv, modregistry.ErrNotFound)
+	}
+	return module.SourceLoc{FS: module.OSDirFS(dir), Dir: "."}, nil
+}
+
+// ModuleVersions returns the bundled versions of the module with the given
+// path in semver order. If mpath has a major version suffix, only versions
+// with that major version are returned.
+func (b *Bundle) ModuleVersions(_ context.Context, mpath string) ([]string, error) {
+	base, major, hasMajor := ast.SplitPackageVersion(mpath)
+	if !hasMajor {
+		base = mpath
+	}
+	entries, err := os.ReadDir(filepath.Join(b.root, filepath.FromSlash(path.Dir(base))))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing schema bundle: %w", err)
+	}
+
+	prefix := path.Base(base) + "@"
+	var versions []string
+	for _, e := range entries {
+		version, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || !e.IsDir() || semver.Canonical(version) != version {
+			continue
+		}
+		if hasMajor && semver.Major(version) != major {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	slices.SortFunc(versions, semver.Compare)
+	return versions, nil
+}
+
+func (b *Bundle) moduleDir(mv module.Version) string {
+	return filepath.Join(b.root, filepath.FromSlash(mv.BasePath())+"@"+mv.Version())
+}
+
+// CreateBundle downloads the requested versions of a module, along with
+// their transitive dependencies, from reg into the directory dest. Versions
+// may be canonical semver tags or "latest". It returns the module versions
+// written to the bundle.
+func CreateBundle(ctx context.Context, reg modconfig.Registry, dest, modulePath string, versions []string) ([]module.Version, error) {
+	var (
+		queue   []module.Version
+		written []module.Version
+		seen    = make(map[module.Version]bool)
+	)
+	for _, v := range versions {
+		if v == "latest" {
+			available, err := reg.ModuleVersions(ctx, modulePath)
+			if err != nil {
+				return nil, fmt.Errorf("listing module versions for %s: %w", modulePath, err)
+			}
+			if len(available) == 0 {
+				return nil, fmt.Errorf("no versions found for module %s", modulePath)
+			}
+			v = available[len(available)-1]
+		}
+		mv, err := module.NewVersion(modulePath, v)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, mv)
+	}
+
+	for len(queue) > 0 {
+		mv := queue[0]
+		queue = queue[1:]
+		if seen[mv] {
+			continue
+		}
+		seen[mv] = true
+
+		loc, err := reg.Fetch(ctx, mv)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %v: %w", mv, err)
+		}
+		dir := filepath.Join(dest, filepath.FromSlash(mv.BasePath())+"@"+mv.Version())
+		if err := copyModule(loc, dir); err != nil {
+			return nil, fmt.Errorf("writing %v: %w", mv, err)
+		}
+		slog.Info("added module to schema bundle", "module", mv.String())
+		written = append(written, mv)
+
+		deps, err := reg.Requirements(ctx, mv)
+		if err != nil {
+			return nil, fmt.Errorf("reading requirements of %v: %w", mv, err)
+		}
+		queue = append(queue, deps...)
+	}
+	return written, nil
+}
+
+// ArchiveBundle writes the bundle directory dir as a gzip-compressed tarball to w.
+func ArchiveBundle(dir string, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := tw.AddFS(os.DirFS(dir)); err != nil {
+		return fmt.Errorf("archiving schema bundle: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func copyModule(loc module.SourceLoc, dest string) error {
+	return fs.WalkDir(loc.FS, loc.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(loc.Dir, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := fs.ReadFile(loc.FS, p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
+
+func extractTarball(archivePath, dest string) (err error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	var r io.Reader = f
+	if strings.HasSuffix(archivePath, ".gz") || strings.HasSuffix(archivePath, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q escapes the bundle directory", hdr.Name)
+		}
+		target := filepath.Join(dest, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(target string, r io.Reader) (err error) {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	_, err = io.Copy(out, r)
+	return err
+}
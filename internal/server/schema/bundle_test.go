@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModulePath = "example.com/schemas"
+
+// writeTestModule writes a minimal CUE module version into a bundle directory.
+func writeTestModule(t *testing.T, root, version, schema string) {
+	t.Helper()
+	dir := filepath.Join(root, filepath.FromSlash(testModulePath)+"@"+version)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cue.mod"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cue.mod", "module.cue"),
+		[]byte("module: \""+testModulePath+"@v0\"\nlanguage: version: \"v0.9.0\"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.cue"), []byte("package schemas\n"+schema), 0o644))
+}
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	root := t.TempDir()
+	writeTestModule(t, root, "v0.1.0", "#Person: {name: string}\n")
+	writeTestModule(t, root, "v0.2.0", "#Person: {name: string, age: int}\n")
+	b, err := OpenBundle(root)
+	require.NoError(t, err)
+	return b
+}
+
+func TestBundleModuleVersions(t *testing.T) {
+	b := newTestBundle(t)
+
+	tests := []struct {
+		name  string
+		mpath string
+		want  []string
+	}{
+		{name: "all versions", mpath: testModulePath, want: []string{"v0.1.0", "v0.2.0"}},
+		{name: "matching major", mpath: testModulePath + "@v0", want: []string{"v0.1.0", "v0.2.0"}},
+		{name: "other major", mpath: testModulePath + "@v1"},
+		{name: "unknown module", mpath: "example.com/other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.ModuleVersions(context.Background(), tt.mpath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCUERegistryFetcherFromBundle(t *testing.T) {
+	b := newTestBundle(t)
+
+	tests := []struct {
+		name       string
+		modulePath string
+		wantAge    bool
+	}{
+		{name: "pinned version", modulePath: testModulePath + "@v0.1.0"},
+		{name: "latest resolves to newest bundled version", modulePath: testModulePath + "@latest", wantAge: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewCUERegistryFetcher(tt.modulePath)
+			f.Registry = b
+			val, _, err := f.Fetch(context.Background())
+			require.NoError(t, err)
+			age := val.LookupPath(cue.ParsePath("#Person.age"))
+			assert.Equal(t, tt.wantAge, age.Exists())
+		})
+	}
+
+	f := NewCUERegistryFetcher(testModulePath + "@v0.3.0")
+	f.Registry = b
+	_, _, err := f.Fetch(context.Background())
+	assert.Error(t, err, "versions missing from the bundle must not be fetched")
+}
+
+func TestCUEVersionResolverFromBundle(t *testing.T) {
+	r := NewCUEVersionResolver(testModulePath)
+	r.Registry = newTestBundle(t)
+	latest, _, err := r.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0", latest)
+}
+
+func TestCreateBundleArchiveRoundTrip(t *testing.T) {
+	src := newTestBundle(t)
+	dest := t.TempDir()
+
+	written, err := CreateBundle(context.Background(), src, dest, testModulePath, []string{"latest"})
+	require.NoError(t, err)
+	require.Len(t, written, 1)
+	assert.Equal(t, testModulePath+"@v0.2.0", written[0].String())
+
+	var buf bytes.Buffer
+	require.NoError(t, ArchiveBundle(dest, &buf))
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0o644))
+
+	b, err := OpenBundle(archive)
+	require.NoError(t, err)
+	extracted := b.Root()
+	t.Cleanup(func() { _ = b.Close() })
+
+	versions, err := b.ModuleVersions(context.Background(), testModulePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.2.0"}, versions)
+
+	f := NewCUERegistryFetcher(testModulePath + "@latest")
+	f.Registry = b
+	_, _, err = f.Fetch(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, b.Close())
+	assert.NoDirExists(t, extracted, "Close removes the extracted tarball")
+}
+
+func TestCUEVersionListFromBundle(t *testing.T) {
+	resolver := NewCUEVersionResolver(testModulePath)
+	resolver.Registry = newTestBundle(t)
+
+	list, source, err := CUEVersionList{Resolver: resolver}.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.1.0", "v0.2.0"}, list)
+	assert.Equal(t, testModulePath, source)
+
+	latest, _, err := resolver.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0", latest)
+
+	resolver = NewCUEVersionResolver("example.com/other")
+	resolver.Registry = newTestBundle(t)
+	_, _, err = CUEVersionList{Resolver: resolver}.Fetch(context.Background())
+	assert.ErrorContains(t, err, "no versions found")
+}
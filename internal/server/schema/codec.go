@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+)
+
+// MarshalValue serializes a built schema as self-contained CUE source,
+// including definitions, optional fields, hidden fields and docs, so it can
+// be persisted and rebuilt without loading the module again.
+func MarshalValue(val cue.Value) ([]byte, error) {
+	syn := val.Syntax(
+		cue.Definitions(true),
+		cue.Optional(true),
+		cue.Hidden(true),
+		cue.Attributes(true),
+		cue.Docs(true),
+	)
+	src, err := format.Node(syn)
+	if err != nil {
+		return nil, fmt.Errorf("serializing schema: %w", err)
+	}
+	return src, nil
+}
+
+// UnmarshalValue builds a schema from CUE source produced by MarshalValue.
+func UnmarshalValue(src []byte) (cue.Value, error) {
+	val := cuecontext.New().CompileBytes(src)
+	if err := val.Err(); err != nil {
+		return cue.Value{}, fmt.Errorf("building serialized schema: %w", err)
+	}
+	return val, nil
+}
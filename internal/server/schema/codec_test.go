@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalValueRoundTrip(t *testing.T) {
+	val := cuecontext.New().CompileString(`
+		import "strings"
+
+		// A person.
+		#Person: {
+			name:  string & strings.MinRunes(1)
+			age?:  int & >=0
+			kind:  "a" | *"b"
+		}
+	`)
+	require.NoError(t, val.Err())
+
+	src, err := MarshalValue(val)
+	require.NoError(t, err)
+	rebuilt, err := UnmarshalValue(src)
+	require.NoError(t, err)
+
+	result, err := Validate(rebuilt, "#Person", "name: Alice\nage: 30")
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	result, err = Validate(rebuilt, "#Person", "name: \"\"")
+	require.NoError(t, err)
+	assert.False(t, result.Valid, "builtin constraints survive serialization")
+}
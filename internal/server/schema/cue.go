@@ -16,6 +16,10 @@ import (
 // CUERegistryFetcher loads a CUE module from the registry and returns a built cue.Value.
 type CUERegistryFetcher struct {
 	modulePath string
+
+	// Registry resolves and fetches CUE modules. If nil, the registry is
+	// configured from the environment with modconfig.NewRegistry.
+	Registry modconfig.Registry
 }
 
 // NewCUERegistryFetcher creates a fetcher for the given CUE module path.
@@ -29,9 +33,9 @@ func NewCUERegistryFetcher(modulePath string) *CUERegistryFetcher {
 func (f *CUERegistryFetcher) Fetch(_ context.Context) (cue.Value, string, error) {
 	slog.Info("loading schema from registry", "module", f.modulePath)
 
-	reg, err := modconfig.NewRegistry(nil)
+	reg, err := registryOrDefault(f.Registry)
 	if err != nil {
-		return cue.Value{}, "", fmt.Errorf("creating CUE registry: %w", err)
+		return cue.Value{}, "", err
 	}
 
 	instances := load.Instances([]string{f.modulePath}, &load.Config{
@@ -52,3 +56,15 @@ func (f *CUERegistryFetcher) Fetch(_ context.Context) (cue.Value, string, error)
 
 	return val, f.modulePath, nil
 }
+
+// registryOrDefault returns reg, or the environment-configured CUE registry when reg is nil.
+func registryOrDefault(reg modconfig.Registry) (modconfig.Registry, error) {
+	if reg != nil {
+		return reg, nil
+	}
+	reg, err := modconfig.NewRegistry(nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating CUE registry: %w", err)
+	}
+	return reg, nil
+}
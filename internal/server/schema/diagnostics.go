@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// artifactFilename is the file name under which submitted YAML is parsed, so
+// error positions in the artifact can be told apart from schema positions.
+const artifactFilename = "artifact.yaml"
+
+// maxActualLength bounds how much of an offending value is echoed back.
+const maxActualLength = 80
+
+// yamlErrorPattern matches YAML syntax errors, which carry their position
+// only as a "file:line[:column]: " message prefix.
+var yamlErrorPattern = regexp.MustCompile(`^` + regexp.QuoteMeta(artifactFilename) + `:(\d+)(?::(\d+))?: (.*)$`)
+
+// Severity classifies a Diagnostic.
+type Severity string
+
+const (
+	// SeverityError marks a schema violation that makes the artifact invalid.
+	SeverityError Severity = "error"
+	// SeverityWarning marks an issue that does not make the artifact invalid.
+	SeverityWarning Severity = "warning"
+)
+
+// Rule classifies the schema check a Diagnostic failed.
+type Rule string
+
+const (
+	// RuleYAMLSyntax marks content that is not well-formed YAML.
+	RuleYAMLSyntax Rule = "yaml-syntax"
+	// RuleMissingField marks a required field that is absent.
+	RuleMissingField Rule = "missing-field"
+	// RuleFieldNotAllowed marks a field the definition does not declare.
+	RuleFieldNotAllowed Rule = "field-not-allowed"
+	// RuleTypeMismatch marks a value of the wrong kind, such as a number
+	// where a string is required.
+	RuleTypeMismatch Rule = "type-mismatch"
+	// RuleInvalidValue marks a value of the right kind that violates a
+	// constraint, such as a pattern, bound or enumeration.
+	RuleInvalidValue Rule = "invalid-value"
+	// RuleSchemaViolation marks any other schema error.
+	RuleSchemaViolation Rule = "schema-violation"
+)
+
+// Diagnostic is a single validation finding located in the submitted YAML.
+type Diagnostic struct {
+	// Path is the field path within the artifact, e.g. controls[3].assessment-requirements[0].id.
+	Path string `json:"path"`
+	// Line and Column locate the offending value (or its nearest present
+	// parent, for missing fields) in the submitted YAML. Zero when unknown.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+	// Expected is the schema constraint the value must satisfy.
+	Expected string `json:"expected,omitempty"`
+	// Actual is the submitted value; empty when the field is missing.
+	Actual   string   `json:"actual,omitempty"`
+	Severity Severity `json:"severity"`
+	// Rule is the kind of check that failed.
+	Rule    Rule   `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// String renders the diagnostic as a single line for plain-text error lists.
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	if d.Line > 0 {
+		fmt.Fprintf(&b, "%d:%d: ", d.Line, d.Column)
+	}
+	if d.Path != "" {
+		b.WriteString(d.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(d.Message)
+	return b.String()
+}
+
+// yamlDiagnostics converts a YAML syntax error into diagnostics.
+func yamlDiagnostics(err error) []Diagnostic {
+	var result []Diagnostic
+	for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+		d := Diagnostic{Severity: SeverityError, Rule: RuleYAMLSyntax, Message: line}
+		if m := yamlErrorPattern.FindStringSubmatch(line); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Column, _ = strconv.Atoi(m[2])
+			d.Message = m[3]
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// diagnostics converts a CUE validation error into diagnostics, one per
+// field path. Errors reported for the alternatives of a disjunction are merged.
+func diagnostics(err error, data cue.Value) []Diagnostic {
+	var (
+		result []Diagnostic
+		byPath = make(map[string]int)
+	)
+	for _, e := range cueerrors.Errors(err) {
+		format, args := e.Msg()
+		if strings.Contains(format, "errors in empty disjunction") {
+			// Summary line; the per-alternative errors that follow carry the detail.
+			continue
+		}
+
+		selectors := artifactSelectors(e.Path())
+		d := Diagnostic{
+			Path:     formatPath(selectors),
+			Severity: SeverityError,
+			Rule:     ruleFromMsg(format),
+			Message:  fmt.Sprintf(format, args...),
+		}
+
+		if value := data.LookupPath(cue.MakePath(selectors...)); value.Exists() {
+			d.Actual = formatActual(value)
+		}
+		d.Expected = expectedFromMsg(format, args, d.Actual)
+		if strings.HasPrefix(format, "incomplete value") {
+			d.Message = fmt.Sprintf("missing required field (expected %s)", d.Expected)
+		}
+		d.Line, d.Column = artifactPosition(e, data, selectors)
+
+		if i, ok := byPath[d.Path]; ok {
+			result[i] = mergeDiagnostic(result[i], d)
+			continue
+		}
+		byPath[d.Path] = len(result)
+		result = append(result, d)
+	}
+	return result
+}
+
+// artifactSelectors converts a CUE error path to selectors relative to the
+// artifact root, dropping the leading definition the artifact was unified with.
+func artifactSelectors(path []string) []cue.Selector {
+	if len(path) > 0 && strings.HasPrefix(path[0], "#") {
+		path = path[1:]
+	}
+	selectors := make([]cue.Selector, 0, len(path))
+	for _, elem := range path {
+		if i, err := strconv.Atoi(elem); err == nil {
+			selectors = append(selectors, cue.Index(i))
+			continue
+		}
+		if s, err := strconv.Unquote(elem); err == nil {
+			selectors = append(selectors, cue.Str(s))
+			continue
+		}
+		selectors = append(selectors, cue.Str(elem))
+	}
+	return selectors
+}
+
+// formatPath renders selectors as a dotted field path with bracketed list
+// indexes, e.g. controls[3].assessment-requirements[0].id.
+func formatPath(selectors []cue.Selector) string {
+	var b strings.Builder
+	for _, sel := range selectors {
+		if sel.Type() == cue.IndexLabel {
+			fmt.Fprintf(&b, "[%d]", sel.Index())
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(sel.Unquoted())
+	}
+	return b.String()
+}
+
+// artifactPosition returns the line and column of the error in the submitted
+// YAML, falling back to the nearest present parent for missing fields.
+func artifactPosition(e cueerrors.Error, data cue.Value, selectors []cue.Selector) (int, int) {
+	positions := append([]token.Pos{e.Position()}, e.InputPositions()...)
+	for _, pos := range positions {
+		if pos.IsValid() && pos.Filename() == artifactFilename {
+			return pos.Line(), pos.Column()
+		}
+	}
+	for i := len(selectors); i > 0; i-- {
+		if v := data.LookupPath(cue.MakePath(selectors[:i]...)); v.Exists() {
+			if pos := v.Pos(); pos.IsValid() {
+				return pos.Line(), pos.Column()
+			}
+		}
+	}
+	return 0, 0
+}
+
+// expectedFromMsg extracts the violated constraint from a CUE error message.
+func expectedFromMsg(format string, args []any, actual string) string {
+	switch {
+	case strings.HasPrefix(format, "invalid value") && len(args) >= 2:
+		return fmt.Sprint(args[1])
+	case strings.HasPrefix(format, "conflicting values") && len(args) >= 2:
+		// The argument order is not fixed; the expected side is the one
+		// that is not the submitted value.
+		first, second := fmt.Sprint(args[0]), fmt.Sprint(args[1])
+		if first == actual {
+			return second
+		}
+		return first
+	case strings.HasPrefix(format, "incomplete value") && len(args) >= 1:
+		return fmt.Sprint(args[0])
+	case strings.Contains(format, "field not allowed"):
+		return "no such field"
+	}
+	return ""
+}
+
+// ruleFromMsg classifies a CUE error by its message format.
+func ruleFromMsg(format string) Rule {
+	switch {
+	case strings.HasPrefix(format, "incomplete value"):
+		return RuleMissingField
+	case strings.Contains(format, "field not allowed"):
+		return RuleFieldNotAllowed
+	case strings.Contains(format, "mismatched types"):
+		return RuleTypeMismatch
+	case strings.HasPrefix(format, "invalid value"), strings.HasPrefix(format, "conflicting values"):
+		return RuleInvalidValue
+	}
+	return RuleSchemaViolation
+}
+
+// formatActual renders a submitted value compactly.
+func formatActual(v cue.Value) string {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		return "{...}"
+	case cue.ListKind:
+		return "[...]"
+	}
+	s := fmt.Sprint(v)
+	if len(s) > maxActualLength {
+		s = s[:maxActualLength] + "..."
+	}
+	return s
+}
+
+// mergeDiagnostic combines two diagnostics for the same path, as produced
+// for each failed alternative of a disjunction.
+func mergeDiagnostic(a, b Diagnostic) Diagnostic {
+	if b.Expected != "" && b.Expected != a.Expected {
+		if a.Expected == "" {
+			a.Expected = b.Expected
+		} else {
+			a.Expected += " | " + b.Expected
+		}
+	}
+	if b.Message != a.Message {
+		a.Message += "; " + b.Message
+	}
+	if a.Line == 0 {
+		a.Line, a.Column = b.Line, b.Column
+	}
+	return a
+}
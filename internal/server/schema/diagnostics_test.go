@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diagnosticsSchema = `
+#Catalog: {
+	id:   =~"^[A-Z]+$"
+	kind: "a" | "b"
+	controls: [...#Control]
+}
+#Control: {
+	id:  string
+	age: int & >=10
+	"assessment-requirements": [...{id: string}]
+}
+`
+
+func TestValidateDiagnostics(t *testing.T) {
+	val := cuecontext.New().CompileString(diagnosticsSchema)
+	require.NoError(t, val.Err())
+
+	tests := []struct {
+		name string
+		yaml string
+		want Diagnostic
+	}{
+		{
+			name: "bound violation",
+			yaml: "id: abc\nkind: a\ncontrols: []",
+			want: Diagnostic{Path: "id", Line: 1, Column: 5, Expected: `=~"^[A-Z]+$"`, Actual: `"abc"`, Severity: SeverityError, Rule: RuleInvalidValue},
+		},
+		{
+			name: "nested list type mismatch",
+			yaml: "id: ABC\nkind: a\ncontrols:\n  - id: x\n    age: 15\n    assessment-requirements:\n      - id: 3\n",
+			want: Diagnostic{Path: "controls[0].assessment-requirements[0].id", Line: 7, Column: 13, Expected: "string", Actual: "3", Severity: SeverityError, Rule: RuleTypeMismatch},
+		},
+		{
+			name: "disjunction alternatives are merged",
+			yaml: "id: ABC\nkind: c\ncontrols: []",
+			want: Diagnostic{Path: "kind", Line: 2, Column: 7, Expected: `"a" | "b"`, Actual: `"c"`, Severity: SeverityError, Rule: RuleInvalidValue},
+		},
+		{
+			name: "missing field located at its parent",
+			yaml: "id: ABC\nkind: a\ncontrols:\n  - age: 20\n    assessment-requirements: []\n",
+			want: Diagnostic{Path: "controls[0].id", Line: 4, Column: 5, Expected: "string", Severity: SeverityError, Rule: RuleMissingField},
+		},
+		{
+			name: "field not allowed",
+			yaml: "id: ABC\nkind: a\ncontrols: []\nextra: 1\n",
+			want: Diagnostic{Path: "extra", Line: 4, Column: 1, Expected: "no such field", Actual: "1", Severity: SeverityError, Rule: RuleFieldNotAllowed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Validate(val, "#Catalog", tt.yaml)
+			require.NoError(t, err)
+			require.False(t, result.Valid)
+			require.Len(t, result.Diagnostics, 1, "%+v", result.Diagnostics)
+
+			got := result.Diagnostics[0]
+			assert.NotEmpty(t, got.Message)
+			got.Message = ""
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateDiagnosticsInvalidYAML(t *testing.T) {
+	val := cuecontext.New().CompileString(diagnosticsSchema)
+	require.NoError(t, val.Err())
+
+	result, err := Validate(val, "#Catalog", "id: [unclosed\n")
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.NotEmpty(t, result.Diagnostics)
+	assert.Equal(t, SeverityError, result.Diagnostics[0].Severity)
+	assert.Equal(t, RuleYAMLSyntax, result.Diagnostics[0].Rule)
+	assert.Equal(t, 1, result.Diagnostics[0].Line)
+}
@@ -6,17 +6,20 @@ import (
 	"fmt"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/format"
 )
 
+var syntaxOptions = []cue.Option{
+	cue.Definitions(true),
+	cue.Optional(true),
+	cue.Attributes(true),
+	cue.Docs(true),
+}
+
 // FormatDefinitions returns the formatted CUE definitions from a built schema value.
 func FormatDefinitions(val cue.Value) (string, error) {
-	syn := val.Syntax(
-		cue.Definitions(true),
-		cue.Optional(true),
-		cue.Attributes(true),
-		cue.Docs(true),
-	)
+	syn := val.Syntax(syntaxOptions...)
 
 	formatted, err := format.Node(syn)
 	if err != nil {
@@ -25,3 +28,30 @@ func FormatDefinitions(val cue.Value) (string, error) {
 
 	return string(formatted), nil
 }
+
+// FormatDefinition returns the formatted CUE declaration of the top-level
+// definition name (e.g. "#Policy") of a built schema value, with its docs.
+func FormatDefinition(val cue.Value, name string) (string, error) {
+	var decls []ast.Decl
+	switch syn := val.Syntax(syntaxOptions...).(type) {
+	case *ast.File:
+		decls = syn.Decls
+	case *ast.StructLit:
+		decls = syn.Elts
+	}
+	for _, decl := range decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if label, _, _ := ast.LabelName(field.Label); label != name {
+			continue
+		}
+		formatted, err := format.Node(field)
+		if err != nil {
+			return "", fmt.Errorf("formatting schema definition %s: %w", name, err)
+		}
+		return string(formatted), nil
+	}
+	return "", fmt.Errorf("schema has no definition %s", name)
+}
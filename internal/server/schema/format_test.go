@@ -33,3 +33,26 @@ func TestFormatDefinitionsEmptyValue(t *testing.T) {
 	_, err := FormatDefinitions(cue.Value{})
 	assert.Error(t, err)
 }
+
+func TestFormatDefinition(t *testing.T) {
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`
+		// Person describes an individual.
+		#Person: {
+			name: string
+			home: #Address
+		}
+		#Address: street: string
+	`)
+	require.NoError(t, val.Err())
+
+	formatted, err := FormatDefinition(val, "#Person")
+	require.NoError(t, err)
+	assert.Contains(t, formatted, "// Person describes an individual.")
+	assert.Contains(t, formatted, "#Person: {")
+	assert.Contains(t, formatted, "home: #Address")
+	assert.NotContains(t, formatted, "street")
+
+	_, err = FormatDefinition(val, "#Missing")
+	assert.ErrorContains(t, err, "schema has no definition #Missing")
+}
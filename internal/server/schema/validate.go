@@ -12,9 +12,10 @@ import (
 
 // ValidateResult holds the outcome of validating YAML against a CUE definition.
 type ValidateResult struct {
-	Valid   bool
-	Errors  []string
-	Message string
+	Valid       bool
+	Errors      []string
+	Diagnostics []Diagnostic
+	Message     string
 }
 
 // Validate checks YAML content against a named CUE definition within the schema.
@@ -24,12 +25,13 @@ func Validate(schema cue.Value, definition, yamlContent string) (ValidateResult,
 		return ValidateResult{}, fmt.Errorf("definition %s not found in schema", definition)
 	}
 
-	yamlFile, err := yaml.Extract("artifact.yaml", yamlContent)
+	yamlFile, err := yaml.Extract(artifactFilename, yamlContent)
 	if err != nil {
 		return ValidateResult{
-			Valid:   false,
-			Errors:  []string{fmt.Sprintf("Failed to parse YAML: %v", err)},
-			Message: fmt.Sprintf("Validation failed: invalid YAML: %v", err),
+			Valid:       false,
+			Errors:      []string{fmt.Sprintf("Failed to parse YAML: %v", err)},
+			Diagnostics: yamlDiagnostics(err),
+			Message:     fmt.Sprintf("Validation failed: invalid YAML: %v", err),
 		}, nil
 	}
 
@@ -52,9 +54,10 @@ func Validate(schema cue.Value, definition, yamlContent string) (ValidateResult,
 			}
 		}
 		return ValidateResult{
-			Valid:   false,
-			Errors:  errors,
-			Message: fmt.Sprintf("Validation failed: %v", err),
+			Valid:       false,
+			Errors:      errors,
+			Diagnostics: diagnostics(err, data),
+			Message:     fmt.Sprintf("Validation failed: %v", err),
 		}, nil
 	}
 
@@ -64,3 +67,19 @@ func Validate(schema cue.Value, definition, yamlContent string) (ValidateResult,
 		Message: "Artifact is valid",
 	}, nil
 }
+
+// Definitions returns the names of the top-level definitions in the schema,
+// in declaration order.
+func Definitions(schema cue.Value) ([]string, error) {
+	iter, err := schema.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, fmt.Errorf("listing schema definitions: %w", err)
+	}
+	var names []string
+	for iter.Next() {
+		if sel := iter.Selector(); sel.IsDefinition() {
+			names = append(names, sel.String())
+		}
+	}
+	return names, nil
+}
@@ -87,3 +87,16 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestDefinitions(t *testing.T) {
+	val := cuecontext.New().CompileString(`
+		#Person: name: string
+		#Team: members: [...#Person]
+		regular: 1
+	`)
+	require.NoError(t, val.Err())
+
+	names, err := Definitions(val)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#Person", "#Team"}, names)
+}
@@ -14,6 +14,10 @@ import (
 // and returns the highest semver tag.
 type CUEVersionResolver struct {
 	modulePath string
+
+	// Registry lists module versions. If nil, the registry is configured
+	// from the environment with modconfig.NewRegistry.
+	Registry modconfig.Registry
 }
 
 // NewCUEVersionResolver creates a CUEVersionResolver for the given module path.
@@ -22,22 +26,45 @@ func NewCUEVersionResolver(modulePath string) *CUEVersionResolver {
 }
 
 func (r *CUEVersionResolver) Fetch(ctx context.Context) (string, string, error) {
-	reg, err := modconfig.NewRegistry(nil)
+	versions, err := r.Versions(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	latest := versions[len(versions)-1]
+	slog.Info("resolved latest module version", "module", r.modulePath, "version", latest)
+	return latest, r.modulePath, nil
+}
+
+// Versions lists every published version of the module in semver order.
+func (r *CUEVersionResolver) Versions(ctx context.Context) ([]string, error) {
+	reg, err := registryOrDefault(r.Registry)
 	if err != nil {
-		return "", "", fmt.Errorf("creating CUE registry: %w", err)
+		return nil, err
 	}
 
 	// modregistry.Client.ModuleVersions returns results sorted in semver order.
 	versions, err := reg.ModuleVersions(ctx, r.modulePath)
 	if err != nil {
-		return "", "", fmt.Errorf("listing module versions for %s: %w", r.modulePath, err)
+		return nil, fmt.Errorf("listing module versions for %s: %w", r.modulePath, err)
 	}
 
 	if len(versions) == 0 {
-		return "", "", fmt.Errorf("no versions found for module %s", r.modulePath)
+		return nil, fmt.Errorf("no versions found for module %s", r.modulePath)
 	}
+	return versions, nil
+}
 
-	latest := versions[len(versions)-1]
-	slog.Info("resolved latest module version", "module", r.modulePath, "version", latest)
-	return latest, r.modulePath, nil
+// CUEVersionList fetches every published version of a module in semver
+// order, so the list can be cached like the latest version.
+type CUEVersionList struct {
+	Resolver *CUEVersionResolver
+}
+
+func (l CUEVersionList) Fetch(ctx context.Context) ([]string, string, error) {
+	versions, err := l.Resolver.Versions(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return versions, l.Resolver.modulePath, nil
 }
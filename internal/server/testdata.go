@@ -196,3 +196,140 @@ controls:
         applicability:
           - default
 `
+
+const testV0GuidanceCatalog = `metadata:
+  id: TEST
+  type: GuidanceCatalog
+  gemara-version: "0.20.0"
+  description: Test guidance catalog
+  version: 1.0.0
+  author:
+    id: test
+    name: Test Author
+    type: Human
+  mapping-references:
+    - id: PRIN
+      title: Test Principles
+      version: "1.0"
+title: Test Guidance Catalog
+document-type: Standard
+front-matter: Introductory text
+families:
+  - id: test-family
+    title: Test Family
+    description: Test family
+guidelines:
+  - id: TEST.G01
+    title: Test Guideline
+    objective: Test objective
+    family: test-family
+    recommendations:
+      - Do the thing
+    guideline-parts:
+      - id: TEST.G01.01
+        text: Test statement
+    principle-mappings:
+      - reference-id: PRIN
+        entries:
+          - reference-id: PRIN.P01
+            strength: 8
+            remarks: Supports principle one
+  - id: TEST.G02
+    title: Second Guideline
+    family: test-family
+    state: Draft
+`
+
+const testV0Policy = `metadata:
+  id: TEST
+  type: Policy
+  gemara-version: "0.20.0"
+  description: Test policy
+  version: 1.0.0
+  author:
+    id: test
+    name: Test Author
+    type: Human
+  mapping-references:
+    - id: CCC
+      title: Test Control Catalog
+      version: "1.0"
+    - id: RISKS
+      title: Test Risk Catalog
+      version: "1.0"
+title: Test Policy
+contacts:
+  responsible:
+    - name: Security Team
+  accountable:
+    - name: CISO
+scope:
+  in:
+    technologies:
+      - Cloud Storage
+imports:
+  policies:
+    - BASE-POLICY
+  catalogs:
+    - reference-id: CCC
+      exclusions:
+        - CCC.C02
+risks:
+  mitigated:
+    - reference-id: RISKS
+      risk-id: RISKS.R01
+  accepted:
+    - reference-id: RISKS
+      risk-id: RISKS.R02
+      justification: Compensating controls in place
+adherence:
+  evaluation-methods:
+    - type: Behavioral
+      mode: Automated
+      description: Nightly scan
+  non-compliance: Notify the accountable contact
+`
+
+const testV0EvaluationLog = `metadata:
+  id: TEST
+  type: EvaluationLog
+  gemara-version: "0.20.0"
+  description: Test evaluation log
+  version: 1.0.0
+  author:
+    id: scanner
+    name: Test Scanner
+    type: Software
+evaluation-set:
+  - name: Access control
+    control-id: TEST.C01
+    result: Passed
+    message: All checks passed
+    assessment-logs:
+      - requirement-id: TEST.C01.TR01
+        applicability:
+          - default
+        description: Check MFA
+        result: Passed
+        message: MFA enforced
+        steps:
+          - checks.mfa
+        steps-executed: 1
+        start: "2025-08-22T16:02:00Z"
+  - name: Encryption
+    control:
+      reference-id: CCC
+      entry-id: TEST.C02
+    result: Failed
+    message: Bucket not encrypted
+    assessment-logs:
+      - requirement:
+          reference-id: CCC
+          entry-id: TEST.C02.TR01
+        description: Check encryption
+        result: Failed
+        message: Bucket not encrypted
+        steps:
+          - checks.encryption
+        start: "2025-08-22T16:02:01Z"
+`
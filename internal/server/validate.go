@@ -6,21 +6,26 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/mod/modconfig"
 	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
+	"github.com/gemaraproj/gemara-mcp/internal/server/report"
 	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/goccy/go-yaml"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/semver"
 )
 
 // MetadataValidateGemaraArtifact describes the ValidateGemaraArtifact tool.
 var MetadataValidateGemaraArtifact = &mcp.Tool{
 	Name:        "validate_gemara_artifact",
-	Description: "Validate a Gemara artifact YAML content against the Gemara CUE schema using the CUE registry module.",
+	Description: "Validate a Gemara artifact YAML content against the Gemara CUE schema using the CUE registry module. When definition or version is omitted, it is inferred from the artifact's metadata.type and metadata.gemara-version; when the artifact has no metadata.type, every top-level definition is tried and the matching ones are reported. Failures are reported as diagnostics with the field path, YAML line and column, expected constraint, actual value, severity and rule. Set format to sarif or junit to also receive the findings as a SARIF 2.1.0 log or JUnit XML report, located at artifact_path.",
 	InputSchema: map[string]interface{}{
 		"type":     "object",
-		"required": []string{"artifact_content", "definition"},
+		"required": []string{"artifact_content"},
 		"properties": map[string]interface{}{
 			"artifact_content": map[string]interface{}{
 				"type":        "string",
@@ -28,54 +33,147 @@ var MetadataValidateGemaraArtifact = &mcp.Tool{
 			},
 			"definition": map[string]interface{}{
 				"type":        "string",
-				"description": "CUE definition name to validate against (e.g., '#ControlCatalog', '#GuidanceCatalog', '#Policy', '#EvaluationLog')",
+				"description": "CUE definition name to validate against (e.g., '#ControlCatalog', '#GuidanceCatalog', '#Policy', '#EvaluationLog'; default: derived from metadata.type)",
 			},
 			"version": map[string]interface{}{
 				"type":        "string",
-				"description": "Version of the Gemara module to validate against (default: 'latest')",
+				"description": "Version of the Gemara module to validate against (default: derived from metadata.gemara-version when a module was published for it, else 'latest')",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"description": "Also encode the findings in report, as a SARIF 2.1.0 log for code-scanning annotations or a JUnit XML test report",
+				"enum":        report.Formats,
+			},
+			"artifact_path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path of the artifact file, used as the findings' location in report (default: '" + defaultArtifactPath + "')",
 			},
 		},
 	},
 }
 
+// defaultArtifactPath locates report findings when no artifact_path is given.
+const defaultArtifactPath = "artifact.yaml"
+
 // InputValidateGemaraArtifact is the input for the ValidateGemaraArtifact tool.
 type InputValidateGemaraArtifact struct {
 	ArtifactContent string `json:"artifact_content"`
-	Definition      string `json:"definition"`
-	Version         string `json:"version"`
+	Definition      string `json:"definition,omitempty"`
+	Version         string `json:"version,omitempty"`
+	Format          string `json:"format,omitempty"`
+	ArtifactPath    string `json:"artifact_path,omitempty"`
 }
 
 // OutputValidateGemaraArtifact is the output for the ValidateGemaraArtifact tool.
 type OutputValidateGemaraArtifact struct {
-	Valid   bool     `json:"valid"`
-	Errors  []string `json:"errors,omitempty"`
-	Message string   `json:"message"`
+	Valid       bool                `json:"valid"`
+	Errors      []string            `json:"errors,omitempty"`
+	Diagnostics []schema.Diagnostic `json:"diagnostics,omitempty"`
+	Message     string              `json:"message"`
+	// Definition and Version are what the artifact was validated against.
+	// Definition is empty when several definitions matched.
+	Definition string `json:"definition,omitempty"`
+	Version    string `json:"version,omitempty"`
+	// Inferred names the inputs ("definition", "version") that were derived
+	// from the artifact's metadata rather than given by the caller.
+	Inferred []string `json:"inferred,omitempty"`
+	// Matches lists the definitions the artifact satisfies when no
+	// definition was given or inferred.
+	Matches []string `json:"matches,omitempty"`
+	// Report holds the findings encoded in the requested format.
+	Report string `json:"report,omitempty"`
+}
+
+// SchemaSource loads the Gemara schema by module version.
+type SchemaSource struct {
+	// Schema returns a cached fetcher for the schema at a module version.
+	Schema func(version string) *fetcher.CachedFetcher[cue.Value]
+	// Versions lists the published module versions, oldest first. When it
+	// is nil, no module version is inferred from metadata.gemara-version.
+	Versions func(ctx context.Context) ([]string, error)
+	// Registry resolves the CUE module dependencies of migrations, such as
+	// an offline schema.Bundle. When it is nil, the registry is configured
+	// from the environment.
+	Registry modconfig.Registry
 }
 
 // ValidateGemaraArtifact validates a Gemara artifact using the CUE Go SDK with the registry module.
 // The returned *mcp.CallToolResult is always nil; the go-sdk derives the
 // tool response from the OutputValidateGemaraArtifact struct.
-func ValidateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputValidateGemaraArtifact, cf *fetcher.CachedFetcher[cue.Value]) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
+func ValidateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputValidateGemaraArtifact, schemas SchemaSource) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
 	// Validate inputs
 	if input.ArtifactContent == "" {
 		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("artifact_content is required")
 	}
-	if input.Definition == "" {
-		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("definition is required")
+	if input.Format != "" && !slices.Contains(report.Formats, input.Format) {
+		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("invalid format %q: must be one of %s", input.Format, strings.Join(report.Formats, ", "))
 	}
 
-	// Ensure definition starts with #
+	result, output, err := validateArtifact(ctx, input, schemas)
+	if err != nil || input.Format == "" {
+		return result, output, err
+	}
+
+	path := input.ArtifactPath
+	if path == "" {
+		path = defaultArtifactPath
+	}
+	artifact := report.Artifact{Path: path, Rule: report.RuleSchema, Diagnostics: output.Diagnostics}
+	if !output.Valid {
+		artifact.Errors = output.Errors
+	}
+	var buf strings.Builder
+	if err := report.Write(&buf, input.Format, "", []report.Artifact{artifact}); err != nil {
+		return nil, OutputValidateGemaraArtifact{}, err
+	}
+	output.Report = buf.String()
+	return result, output, nil
+}
+
+// validateArtifact validates input against the schema, inferring the
+// definition and version from the artifact's metadata when not given.
+func validateArtifact(ctx context.Context, input InputValidateGemaraArtifact, schemas SchemaSource) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
+	var output OutputValidateGemaraArtifact
+
+	// Unreadable YAML leaves the metadata empty; the parse error is
+	// reported by validation itself.
+	meta, _ := readArtifactMetadata(input.ArtifactContent)
+
 	definition := input.Definition
-	if !strings.HasPrefix(definition, "#") {
-		definition = "#" + definition
+	if definition == "" && meta.Metadata.Type != "" {
+		definition = meta.Metadata.Type
+		output.Inferred = append(output.Inferred, "definition")
+	}
+	if definition != "" {
+		definition = normalizeDefinition(definition)
 	}
 
-	slog.Info("validating artifact", "definition", definition, "content_length", len(input.ArtifactContent))
+	version := input.Version
+	if version == "" {
+		version = defaultSchemaVersion
+		if v, ok := schemas.publishedVersion(ctx, meta.Metadata.GemaraVersion); ok {
+			version = v
+			output.Inferred = append(output.Inferred, "version")
+		}
+	}
+
+	slog.Info("validating artifact", "definition", definition, "version", version, "inferred", output.Inferred, "content_length", len(input.ArtifactContent))
 
-	cueVal, _, err := cf.Fetch(ctx, false)
+	cueVal, _, err := schemas.Schema(version).Fetch(ctx, false)
 	if err != nil {
 		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("loading schema: %w", err)
 	}
+	output.Version = version
+
+	if definition == "" {
+		var doc any
+		if err := yaml.Unmarshal([]byte(input.ArtifactContent), &doc); err != nil {
+			output.Errors = []string{fmt.Sprintf("Failed to parse YAML: %v", err)}
+			output.Message = fmt.Sprintf("Validation failed: invalid YAML: %v", err)
+			return nil, output, nil
+		}
+		return matchDefinitions(cueVal, input.ArtifactContent, output)
+	}
 
 	result, err := schema.Validate(cueVal, definition, input.ArtifactContent)
 	if err != nil {
@@ -83,9 +181,120 @@ func ValidateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input I
 	}
 
 	slog.Info("validation complete", "definition", definition, "valid", result.Valid, "error_count", len(result.Errors))
-	return nil, OutputValidateGemaraArtifact{
-		Valid:   result.Valid,
-		Errors:  result.Errors,
-		Message: result.Message,
-	}, nil
+	output.Valid = result.Valid
+	output.Errors = result.Errors
+	output.Diagnostics = result.Diagnostics
+	output.Message = result.Message
+	output.Definition = definition
+	return nil, output, nil
+}
+
+// matchDefinitions validates content against every top-level definition of
+// the schema and reports the ones it satisfies.
+func matchDefinitions(cueVal cue.Value, content string, output OutputValidateGemaraArtifact) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
+	definitions, err := schema.Definitions(cueVal)
+	if err != nil {
+		return nil, OutputValidateGemaraArtifact{}, err
+	}
+
+	for _, definition := range definitions {
+		result, err := schema.Validate(cueVal, definition, content)
+		if err != nil {
+			return nil, OutputValidateGemaraArtifact{}, err
+		}
+		if result.Valid {
+			output.Matches = append(output.Matches, definition)
+		}
+	}
+
+	slog.Info("definition detection complete", "candidates", len(definitions), "matches", output.Matches)
+	switch len(output.Matches) {
+	case 0:
+		output.Errors = []string{"artifact does not match any schema definition"}
+		output.Message = "Validation failed: definition not provided, metadata.type is missing, and the artifact does not match any schema definition"
+	case 1:
+		output.Valid = true
+		output.Definition = output.Matches[0]
+		output.Message = fmt.Sprintf("Artifact is valid as %s", output.Definition)
+	default:
+		output.Valid = true
+		output.Message = fmt.Sprintf("Artifact is valid as each of %s; set definition or metadata.type to choose one", strings.Join(output.Matches, ", "))
+	}
+	return nil, output, nil
+}
+
+// normalizeDefinition ensures a definition name starts with "#".
+func normalizeDefinition(definition string) string {
+	if !strings.HasPrefix(definition, "#") {
+		return "#" + definition
+	}
+	return definition
+}
+
+// artifactMetadata holds the metadata fields used to identify an artifact.
+type artifactMetadata struct {
+	Metadata struct {
+		ID            string `yaml:"id"`
+		Type          string `yaml:"type"`
+		GemaraVersion string `yaml:"gemara-version"`
+	} `yaml:"metadata"`
+}
+
+// readArtifactMetadata reads the metadata fields used to identify an artifact.
+func readArtifactMetadata(content string) (artifactMetadata, error) {
+	var meta artifactMetadata
+	if err := yaml.Unmarshal([]byte(content), &meta); err != nil {
+		return artifactMetadata{}, fmt.Errorf("reading artifact metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// definitionFromMetadata derives the CUE definition for an artifact from its metadata.type.
+func definitionFromMetadata(content string) (string, error) {
+	meta, err := readArtifactMetadata(content)
+	if err != nil {
+		return "", err
+	}
+	if meta.Metadata.Type == "" {
+		return "", fmt.Errorf("definition not provided and metadata.type is missing")
+	}
+	return normalizeDefinition(meta.Metadata.Type), nil
+}
+
+// moduleVersion maps a metadata.gemara-version value such as "1.0" or
+// "v0.20.0" to the corresponding module version. It reports false when
+// the value is empty or not a semantic version.
+func moduleVersion(gemaraVersion string) (string, bool) {
+	v := strings.TrimSpace(gemaraVersion)
+	if v == "" {
+		return "", false
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return "", false
+	}
+	return semver.Canonical(v), true
+}
+
+// publishedVersion returns the module version for a metadata.gemara-version
+// value when a module was published under it. metadata.gemara-version names
+// a spec release, which need not exist as a module version; reporting false
+// lets callers use the latest schema instead of a fetch that would fail.
+func (s SchemaSource) publishedVersion(ctx context.Context, gemaraVersion string) (string, bool) {
+	v, ok := moduleVersion(gemaraVersion)
+	if !ok || s.Versions == nil {
+		return "", false
+	}
+	versions, err := s.Versions(ctx)
+	if err != nil {
+		slog.Warn("failed to list schema versions", "error", err)
+		return "", false
+	}
+	if !slices.Contains(versions, v) {
+		slog.Debug("no module published for gemara-version, using latest", "gemara_version", gemaraVersion)
+		return "", false
+	}
+	return v, true
 }
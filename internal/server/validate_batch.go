@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
+	"github.com/gemaraproj/gemara-mcp/internal/server/schema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxBatchDocuments bounds the number of documents validated in one call.
+const maxBatchDocuments = 200
+
+// MetadataValidateGemaraArtifacts describes the ValidateGemaraArtifacts tool.
+var MetadataValidateGemaraArtifacts = &mcp.Tool{
+	Name:        "validate_gemara_artifacts",
+	Description: "Validate several Gemara artifacts in one call, given as a list and/or a multi-document YAML stream separated by '---'. Each document is validated against its own definition, taken from metadata.type when not given, and gets its own result.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifacts": map[string]interface{}{
+				"type":        "array",
+				"description": "Artifacts to validate",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"artifact_content"},
+					"properties": map[string]interface{}{
+						"artifact_content": map[string]interface{}{
+							"type":        "string",
+							"description": "YAML content of the Gemara artifact",
+						},
+						"definition": map[string]interface{}{
+							"type":        "string",
+							"description": "CUE definition name (default: derived from metadata.type)",
+						},
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Label echoed back in the result, such as a file name",
+						},
+					},
+				},
+			},
+			"artifact_stream": map[string]interface{}{
+				"type":        "string",
+				"description": "Multi-document YAML stream of Gemara artifacts separated by '---'",
+			},
+			"definition": map[string]interface{}{
+				"type":        "string",
+				"description": "CUE definition name applied to documents without their own (default: derived from each document's metadata.type)",
+			},
+			"version": map[string]interface{}{
+				"type":        "string",
+				"description": "Version of the Gemara module to validate against (default: 'latest')",
+			},
+		},
+	},
+}
+
+// BatchArtifact is one artifact submitted to a tool that takes several
+// artifacts, such as ValidateGemaraArtifacts.
+type BatchArtifact struct {
+	ArtifactContent string `json:"artifact_content"`
+	Definition      string `json:"definition,omitempty"`
+	Name            string `json:"name,omitempty"`
+}
+
+// InputValidateGemaraArtifacts is the input for the ValidateGemaraArtifacts tool.
+type InputValidateGemaraArtifacts struct {
+	Artifacts      []BatchArtifact `json:"artifacts,omitempty"`
+	ArtifactStream string          `json:"artifact_stream,omitempty"`
+	Definition     string          `json:"definition,omitempty"`
+	Version        string          `json:"version,omitempty"`
+}
+
+// BatchValidationResult is the validation outcome for one document.
+type BatchValidationResult struct {
+	Name string `json:"name"`
+	// Line is the first line of the document within artifact_stream; diagnostic
+	// lines are relative to the stream. Zero for entries of artifacts.
+	Line        int                 `json:"line,omitempty"`
+	Definition  string              `json:"definition,omitempty"`
+	Valid       bool                `json:"valid"`
+	Errors      []string            `json:"errors,omitempty"`
+	Diagnostics []schema.Diagnostic `json:"diagnostics,omitempty"`
+	Message     string              `json:"message"`
+}
+
+// OutputValidateGemaraArtifacts is the output for the ValidateGemaraArtifacts tool.
+type OutputValidateGemaraArtifacts struct {
+	Valid   bool                    `json:"valid"`
+	Results []BatchValidationResult `json:"results"`
+	Message string                  `json:"message"`
+}
+
+// batchDocument is a single document queued for validation.
+type batchDocument struct {
+	name       string
+	line       int
+	definition string
+	content    string
+}
+
+// ValidateGemaraArtifacts validates every submitted document in parallel
+// against a single schema loaded through cf.
+// The returned *mcp.CallToolResult is always nil; the go-sdk derives the
+// tool response from the OutputValidateGemaraArtifacts struct.
+func ValidateGemaraArtifacts(ctx context.Context, _ *mcp.CallToolRequest, input InputValidateGemaraArtifacts, cf *fetcher.CachedFetcher[cue.Value]) (*mcp.CallToolResult, OutputValidateGemaraArtifacts, error) {
+	docs := batchDocuments(input)
+	if len(docs) == 0 {
+		return nil, OutputValidateGemaraArtifacts{}, fmt.Errorf("artifacts or artifact_stream is required")
+	}
+	if len(docs) > maxBatchDocuments {
+		return nil, OutputValidateGemaraArtifacts{}, fmt.Errorf("too many documents: %d exceeds the limit of %d", len(docs), maxBatchDocuments)
+	}
+
+	cueVal, _, err := cf.Fetch(ctx, false)
+	if err != nil {
+		return nil, OutputValidateGemaraArtifacts{}, fmt.Errorf("loading schema: %w", err)
+	}
+
+	slog.Info("validating artifact batch", "documents", len(docs))
+
+	results := make([]BatchValidationResult, len(docs))
+	var g errgroup.Group
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i, doc := range docs {
+		g.Go(func() error {
+			results[i] = validateBatchDocument(cueVal, doc)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	validCount := 0
+	for _, r := range results {
+		if r.Valid {
+			validCount++
+		}
+	}
+
+	slog.Info("batch validation complete", "documents", len(docs), "valid", validCount)
+	return nil, OutputValidateGemaraArtifacts{
+		Valid:   validCount == len(results),
+		Results: results,
+		Message: fmt.Sprintf("%d of %d documents valid", validCount, len(results)),
+	}, nil
+}
+
+// batchDocuments flattens the listed artifacts and the documents of the
+// stream into one queue, in input order.
+func batchDocuments(input InputValidateGemaraArtifacts) []batchDocument {
+	var docs []batchDocument
+	for i, a := range input.Artifacts {
+		name := a.Name
+		if name == "" {
+			name = fmt.Sprintf("artifacts[%d]", i)
+		}
+		definition := a.Definition
+		if definition == "" {
+			definition = input.Definition
+		}
+		docs = append(docs, batchDocument{name: name, definition: definition, content: a.ArtifactContent})
+	}
+	for i, d := range splitYAMLDocuments(input.ArtifactStream) {
+		docs = append(docs, batchDocument{
+			name:       fmt.Sprintf("document %d", i+1),
+			line:       d.startLine,
+			definition: input.Definition,
+			content:    d.content,
+		})
+	}
+	return docs
+}
+
+func validateBatchDocument(cueVal cue.Value, doc batchDocument) BatchValidationResult {
+	result := BatchValidationResult{Name: doc.name, Line: doc.line}
+
+	definition := doc.definition
+	if definition == "" {
+		var err error
+		definition, err = definitionFromMetadata(doc.content)
+		if err != nil {
+			result.Message = fmt.Sprintf("Validation failed: %v", err)
+			return result
+		}
+	}
+	definition = normalizeDefinition(definition)
+	result.Definition = definition
+
+	res, err := schema.Validate(cueVal, definition, doc.content)
+	if err != nil {
+		result.Message = fmt.Sprintf("Validation failed: %v", err)
+		return result
+	}
+
+	result.Valid = res.Valid
+	result.Errors = res.Errors
+	result.Message = res.Message
+	result.Diagnostics = res.Diagnostics
+	if doc.line > 1 {
+		for i := range result.Diagnostics {
+			if result.Diagnostics[i].Line > 0 {
+				result.Diagnostics[i].Line += doc.line - 1
+			}
+		}
+	}
+	return result
+}
+
+// yamlDocument is one document of a multi-document YAML stream.
+type yamlDocument struct {
+	content string
+	// startLine is the 1-based line in the stream where the document begins.
+	startLine int
+}
+
+// splitYAMLDocuments splits a YAML stream on "---" and "..." markers,
+// dropping documents that hold only whitespace and comments.
+func splitYAMLDocuments(stream string) []yamlDocument {
+	var (
+		docs  []yamlDocument
+		b     strings.Builder
+		start = 1
+	)
+	flush := func(next int) {
+		if hasYAMLContent(b.String()) {
+			docs = append(docs, yamlDocument{content: b.String(), startLine: start})
+		}
+		b.Reset()
+		start = next
+	}
+
+	for i, line := range strings.SplitAfter(stream, "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimRight(line, " \t\r\n")
+		switch {
+		case trimmed == "---" || trimmed == "...":
+			flush(lineNo + 1)
+		case strings.HasPrefix(trimmed, "--- "):
+			// Content may follow the marker on the same line.
+			flush(lineNo)
+			b.WriteString(strings.TrimPrefix(line, "--- "))
+		default:
+			b.WriteString(line)
+		}
+	}
+	flush(0)
+	return docs
+}
+
+func hasYAMLContent(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/gemaraproj/gemara-mcp/internal/server/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSchemaFetcher serves a schema compiled from in-memory CUE source.
+type staticSchemaFetcher struct {
+	src string
+}
+
+func (f staticSchemaFetcher) Fetch(_ context.Context) (cue.Value, string, error) {
+	val := cuecontext.New().CompileString(f.src)
+	return val, "static", val.Err()
+}
+
+const batchTestSchema = `
+#Metadata: {
+	id:   string
+	type: string
+}
+#ThreatCatalog: {
+	metadata: #Metadata
+	threats: [...{id: string}]
+}
+#ControlCatalog: {
+	metadata: #Metadata
+	controls: [...{id: string}]
+}
+`
+
+func newStaticSchemaCachedFetcher(src string) *fetcher.CachedFetcher[cue.Value] {
+	return fetcher.NewCachedFetcher[cue.Value](staticSchemaFetcher{src: src}, fetcher.NewCache[cue.Value](time.Hour), "static")
+}
+
+func TestSplitYAMLDocuments(t *testing.T) {
+	stream := "# leading comment\n---\na: 1\n---\n# only a comment\n---\nb: 2\nc: 3\n...\n--- d: 4\n"
+	docs := splitYAMLDocuments(stream)
+	require.Len(t, docs, 3)
+	assert.Equal(t, yamlDocument{content: "a: 1\n", startLine: 3}, docs[0])
+	assert.Equal(t, yamlDocument{content: "b: 2\nc: 3\n", startLine: 7}, docs[1])
+	assert.Equal(t, yamlDocument{content: "d: 4\n", startLine: 10}, docs[2])
+}
+
+func TestValidateGemaraArtifacts(t *testing.T) {
+	cf := newStaticSchemaCachedFetcher(batchTestSchema)
+
+	stream := `metadata:
+  id: TC
+  type: ThreatCatalog
+threats:
+  - id: T1
+---
+metadata:
+  id: CC
+  type: ControlCatalog
+controls:
+  - id: 42
+---
+controls: []
+`
+	_, output, err := ValidateGemaraArtifacts(context.Background(), nil, InputValidateGemaraArtifacts{
+		Artifacts: []BatchArtifact{
+			{Name: "explicit.yaml", Definition: "ControlCatalog", ArtifactContent: "metadata: {id: X, type: Other}\ncontrols: []\n"},
+		},
+		ArtifactStream: stream,
+	}, cf)
+	require.NoError(t, err)
+	require.Len(t, output.Results, 4)
+	assert.False(t, output.Valid)
+	assert.Equal(t, "2 of 4 documents valid", output.Message)
+
+	explicit := output.Results[0]
+	assert.Equal(t, "explicit.yaml", explicit.Name)
+	assert.Equal(t, "#ControlCatalog", explicit.Definition, "explicit definition wins over metadata.type")
+	assert.True(t, explicit.Valid)
+
+	threats := output.Results[1]
+	assert.Equal(t, "document 1", threats.Name)
+	assert.Equal(t, "#ThreatCatalog", threats.Definition)
+	assert.True(t, threats.Valid)
+
+	controls := output.Results[2]
+	assert.Equal(t, "#ControlCatalog", controls.Definition)
+	assert.Equal(t, 7, controls.Line)
+	assert.False(t, controls.Valid)
+	require.Len(t, controls.Diagnostics, 1)
+	assert.Equal(t, "controls[0].id", controls.Diagnostics[0].Path)
+	assert.Equal(t, 11, controls.Diagnostics[0].Line, "diagnostic lines are relative to the stream")
+
+	untyped := output.Results[3]
+	assert.False(t, untyped.Valid)
+	assert.Contains(t, untyped.Message, "metadata.type is missing")
+}
+
+func TestValidateGemaraArtifactsRequiresInput(t *testing.T) {
+	cf := newStaticSchemaCachedFetcher(batchTestSchema)
+	_, _, err := ValidateGemaraArtifacts(context.Background(), nil, InputValidateGemaraArtifacts{ArtifactStream: "# nothing\n"}, cf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "artifacts or artifact_stream is required")
+}
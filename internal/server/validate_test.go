@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -49,15 +50,6 @@ func TestValidateGemaraArtifact(t *testing.T) {
 			wantErr:     true,
 			errContains: "artifact_content is required",
 		},
-		{
-			name: "missing definition",
-			input: InputValidateGemaraArtifact{
-				ArtifactContent: "test: content",
-				Definition:      "",
-			},
-			wantErr:     true,
-			errContains: "definition is required",
-		},
 		{
 			name: "valid ControlCatalog from testdata",
 			input: InputValidateGemaraArtifact{
@@ -157,6 +149,7 @@ metadata:
 	}
 
 	cf := newIntegrationSchemaCachedFetcher()
+	schemas := SchemaSource{Schema: func(string) *fetcher.CachedFetcher[cue.Value] { return cf }}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
@@ -166,7 +159,7 @@ metadata:
 				},
 			}
 
-			_, output, err := ValidateGemaraArtifact(ctx, req, tt.input, cf)
+			_, output, err := ValidateGemaraArtifact(ctx, req, tt.input, schemas)
 
 			if tt.wantErr {
 				require.Error(t, err, "should return error")
@@ -187,6 +180,218 @@ metadata:
 	}
 }
 
+func TestValidateGemaraArtifactInference(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          InputValidateGemaraArtifact
+		wantValid      bool
+		wantDefinition string
+		wantVersion    string
+		wantInferred   []string
+		wantMatches    []string
+	}{
+		{
+			name: "definition from metadata.type",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "metadata: {id: TC, type: ThreatCatalog}\nthreats: [{id: T1}]\n",
+			},
+			wantValid:      true,
+			wantDefinition: "#ThreatCatalog",
+			wantVersion:    "latest",
+			wantInferred:   []string{"definition"},
+		},
+		{
+			name: "definition and version from metadata",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "metadata: {id: CC, type: ControlCatalog, gemara-version: \"1.0\"}\ncontrols: [{id: 42}]\n",
+			},
+			wantValid:      false,
+			wantDefinition: "#ControlCatalog",
+			wantVersion:    "v1.0.0",
+			wantInferred:   []string{"definition", "version"},
+		},
+		{
+			name: "explicit inputs take precedence",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "metadata: {id: CC, type: ThreatCatalog, gemara-version: v0.1.0}\ncontrols: []\n",
+				Definition:      "ControlCatalog",
+				Version:         "v0.2.0",
+			},
+			wantValid:      true,
+			wantDefinition: "#ControlCatalog",
+			wantVersion:    "v0.2.0",
+		},
+		{
+			name: "unpublished gemara-version uses latest",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "metadata: {id: TC, type: ThreatCatalog, gemara-version: \"9.9\"}\nthreats: []\n",
+			},
+			wantValid:      true,
+			wantDefinition: "#ThreatCatalog",
+			wantVersion:    "latest",
+			wantInferred:   []string{"definition"},
+		},
+		{
+			name: "non-semver gemara-version is ignored",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "metadata: {id: TC, type: ThreatCatalog, gemara-version: draft}\nthreats: []\n",
+			},
+			wantValid:      true,
+			wantDefinition: "#ThreatCatalog",
+			wantVersion:    "latest",
+			wantInferred:   []string{"definition"},
+		},
+		{
+			name: "single matching definition",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "id: T1\n",
+			},
+			wantValid:      true,
+			wantDefinition: "#Threat",
+			wantVersion:    "latest",
+			wantMatches:    []string{"#Threat"},
+		},
+		{
+			name: "several matching definitions",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "metadata: {id: X}\n",
+			},
+			wantValid:   true,
+			wantVersion: "latest",
+			wantMatches: []string{"#ThreatCatalog", "#ControlCatalog"},
+		},
+		{
+			name: "no matching definition",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "unknown: true\n",
+			},
+			wantValid:   false,
+			wantVersion: "latest",
+		},
+	}
+
+	const inferenceSchema = `
+#Threat: id: string
+#ThreatCatalog: {
+	metadata: {id: string, type?: string, "gemara-version"?: string}
+	threats?: [...#Threat]
+}
+#ControlCatalog: {
+	metadata: {id: string, type?: string, "gemara-version"?: string}
+	controls?: [...{id: string}]
+}
+`
+	published := []string{"v0.1.0", "v0.2.0", "v1.0.0"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := SchemaSource{
+				Schema: func(version string) *fetcher.CachedFetcher[cue.Value] {
+					if version != defaultSchemaVersion && !slices.Contains(published, version) {
+						t.Errorf("fetched unpublished schema version %s", version)
+						return newStaticSchemaCachedFetcher("invalid: {")
+					}
+					return newStaticSchemaCachedFetcher(inferenceSchema)
+				},
+				Versions: func(context.Context) ([]string, error) { return published, nil },
+			}
+			_, output, err := ValidateGemaraArtifact(context.Background(), nil, tt.input, schemas)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantValid, output.Valid, output.Message)
+			assert.Equal(t, tt.wantDefinition, output.Definition)
+			assert.Equal(t, tt.wantVersion, output.Version)
+			assert.Equal(t, tt.wantInferred, output.Inferred)
+			assert.Equal(t, tt.wantMatches, output.Matches)
+			if !tt.wantValid {
+				assert.NotEmpty(t, output.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateGemaraArtifactReport(t *testing.T) {
+	schemas := SchemaSource{Schema: func(string) *fetcher.CachedFetcher[cue.Value] {
+		return newStaticSchemaCachedFetcher("#Threat: {id: string, title: string}")
+	}}
+
+	tests := []struct {
+		name         string
+		input        InputValidateGemaraArtifact
+		wantContains []string
+		wantErr      string
+	}{
+		{
+			name: "sarif",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "id: 42\ntitle: Example\n",
+				Definition:      "#Threat",
+				Format:          "sarif",
+				ArtifactPath:    "threats/T1.yaml",
+			},
+			wantContains: []string{`"ruleId": "gemara/type-mismatch"`, `"uri": "threats/T1.yaml"`, `"startLine": 1`},
+		},
+		{
+			name: "junit with default path",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "id: T1\n",
+				Definition:      "#Threat",
+				Format:          "junit",
+			},
+			wantContains: []string{`<testcase name="artifact.yaml"`, `type="gemara/missing-field"`},
+		},
+		{
+			name: "valid artifact",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "id: T1\ntitle: Example\n",
+				Definition:      "#Threat",
+				Format:          "junit",
+			},
+			wantContains: []string{`failures="0"`},
+		},
+		{
+			name: "unknown format",
+			input: InputValidateGemaraArtifact{
+				ArtifactContent: "id: T1\n",
+				Format:          "html",
+			},
+			wantErr: `invalid format "html"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ValidateGemaraArtifact(context.Background(), nil, tt.input, schemas)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Report, want)
+			}
+		})
+	}
+}
+
+func TestModuleVersion(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{in: "1.0", want: "v1.0.0", wantOK: true},
+		{in: "0.20.0", want: "v0.20.0", wantOK: true},
+		{in: "v1", want: "v1.0.0", wantOK: true},
+		{in: ""},
+		{in: "draft"},
+	}
+	for _, tt := range tests {
+		got, ok := moduleVersion(tt.in)
+		assert.Equal(t, tt.wantOK, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
 // boolPtr returns a pointer to the given bool value.
 func boolPtr(b bool) *bool {
 	return &b
@@ -15,6 +15,6 @@ func main() {
 	defer cancel()
 
 	if err := cli.New().ExecuteContext(ctx); err != nil {
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }